@@ -50,7 +50,8 @@ func completeBranches(cmd *cobra.Command, args []string, toComplete string) ([]s
 	return branches, cobra.ShellCompDirectiveNoFileComp
 }
 
-// completeWorktreeBranches returns branches that have active worktrees.
+// completeWorktreeBranches returns branches that have active worktrees, and
+// the paths of detached worktrees.
 func completeWorktreeBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if len(args) > 0 {
 		return nil, cobra.ShellCompDirectiveNoFileComp
@@ -64,26 +65,65 @@ func completeWorktreeBranches(cmd *cobra.Command, args []string, toComplete stri
 		if i == 0 {
 			continue // Skip main worktree
 		}
+		if wt.Detached {
+			branches = append(branches, wt.Path)
+			continue
+		}
 		branches = append(branches, wt.Branch)
 	}
 	return branches, cobra.ShellCompDirectiveNoFileComp
 }
 
+// completeFromFlag returns local branches for the --from flag.
+func completeFromFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	branches, err := git.ListLocalBranches()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return branches, cobra.ShellCompDirectiveNoFileComp
+}
+
 func init() {
 	rootCmd.AddCommand(completionCmd)
 
 	// Register dynamic completions for the root command (wt <branch>)
 	rootCmd.ValidArgsFunction = completeBranches
 
-	// Register dynamic completions for remove command
+	// Register dynamic completions for run command (branch only; the command
+	// after -- is completed by the shell)
+	runCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return completeBranches(cmd, args, toComplete)
+	}
+
+	// Register dynamic completions for commands that take an existing worktree
 	removeCmd.ValidArgsFunction = completeWorktreeBranches
+	lockCmd.ValidArgsFunction = completeWorktreeBranches
+	mvCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		}
+		return completeWorktreeBranches(cmd, args, toComplete)
+	}
+	unlockCmd.ValidArgsFunction = completeWorktreeBranches
+	renameCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeWorktreeBranches(cmd, args, toComplete)
+	}
 
-	// Register completion for --from flag on root command
-	_ = rootCmd.RegisterFlagCompletionFunc("from", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		branches, err := git.ListLocalBranches()
-		if err != nil {
+	// Logs outlive rolled-back worktrees, so complete all local branches
+	logsCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
-		return branches, cobra.ShellCompDirectiveNoFileComp
-	})
+		return completeBranches(cmd, args, toComplete)
+	}
+
+	// Register completion for --from flag on root command
+	_ = rootCmd.RegisterFlagCompletionFunc("from", completeFromFlag)
+	_ = runCmd.RegisterFlagCompletionFunc("from", completeFromFlag)
 }
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/git"
+)
+
+var foreachParallel int
+var foreachFilter string
+var foreachIncludeMain bool
+
+var foreachCmd = &cobra.Command{
+	Use:   "foreach [--parallel N] [--filter glob] -- <command> [args...]",
+	Short: "run a command in every worktree",
+	Long: `Runs the command in each linked worktree using a bounded worker pool.
+
+Each output line is prefixed with the worktree's branch name. A summary of
+exit codes and durations is printed at the end, and wt exits non-zero if
+the command failed in any worktree.
+
+Examples:
+  wt foreach -- git status --short
+  wt foreach --parallel 4 --filter 'feature/*' -- go test ./...`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.ArgsLenAtDash() != 0 || len(args) == 0 {
+			return fmt.Errorf("usage: wt foreach [flags] -- <command> [args...]")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if foreachParallel < 1 {
+			return fmt.Errorf("--parallel must be at least 1")
+		}
+		if _, err := path.Match(foreachFilter, ""); err != nil {
+			return fmt.Errorf("invalid --filter pattern %q: %w", foreachFilter, err)
+		}
+
+		worktrees, err := git.ListWorktrees()
+		if err != nil {
+			return err
+		}
+
+		var targets []git.Worktree
+		for i, wt := range worktrees {
+			if i == 0 && !foreachIncludeMain {
+				continue // Skip main worktree
+			}
+			if wt.Bare || wt.Prunable {
+				continue // No directory to run in
+			}
+			if foreachFilter != "" {
+				if ok, _ := path.Match(foreachFilter, wt.Branch); !ok {
+					continue
+				}
+			}
+			targets = append(targets, wt)
+		}
+
+		if len(targets) == 0 {
+			fmt.Fprintln(os.Stderr, "No worktrees matched.")
+			return nil
+		}
+
+		results := runForEach(targets, args, foreachParallel)
+
+		failed := printForEachSummary(os.Stdout, results)
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "Command failed in %d of %d worktrees.\n", failed, len(results))
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// foreachResult records the outcome of running the command in one worktree.
+type foreachResult struct {
+	Worktree git.Worktree
+	ExitCode int
+	Err      error
+	Duration time.Duration
+}
+
+// runForEach runs argv in each worktree with at most parallel concurrent
+// processes. Results are returned in the same order as worktrees.
+func runForEach(worktrees []git.Worktree, argv []string, parallel int) []foreachResult {
+	results := make([]foreachResult, len(worktrees))
+	var outMu sync.Mutex
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallel && w < len(worktrees); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = runInWorktree(worktrees[i], argv, &outMu)
+			}
+		}()
+	}
+	for i := range worktrees {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// runInWorktree runs argv in a single worktree, prefixing each output line
+// with the branch name.
+func runInWorktree(wt git.Worktree, argv []string, outMu *sync.Mutex) foreachResult {
+	prefix := "[" + wt.Branch + "] "
+	stdout := &prefixWriter{w: os.Stdout, mu: outMu, prefix: prefix}
+	stderr := &prefixWriter{w: os.Stderr, mu: outMu, prefix: prefix}
+
+	c := exec.Command(argv[0], argv[1:]...)
+	c.Dir = wt.Path
+	c.Stdout = stdout
+	c.Stderr = stderr
+
+	start := time.Now()
+	err := c.Run()
+	res := foreachResult{Worktree: wt, Duration: time.Since(start)}
+
+	stdout.Flush()
+	stderr.Flush()
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			res.ExitCode = exitErr.ExitCode()
+		} else {
+			res.ExitCode = -1
+			res.Err = err
+		}
+	}
+	return res
+}
+
+// printForEachSummary writes a table of exit codes and durations and returns
+// the number of worktrees in which the command failed.
+func printForEachSummary(w io.Writer, results []foreachResult) int {
+	failed := 0
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "BRANCH\tEXIT\tDURATION")
+	for _, r := range results {
+		exit := fmt.Sprintf("%d", r.ExitCode)
+		if r.Err != nil {
+			exit = fmt.Sprintf("error: %v", r.Err)
+		}
+		if r.ExitCode != 0 || r.Err != nil {
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Worktree.Branch, exit, r.Duration.Round(time.Millisecond))
+	}
+	_ = tw.Flush()
+	return failed
+}
+
+// prefixWriter writes complete lines to w, each preceded by prefix.
+// Partial lines are buffered until a newline arrives or Flush is called.
+// Writes to the underlying writer are serialized through mu so lines from
+// concurrent commands never interleave.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    bytes.Buffer
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf.Write(b)
+	for {
+		idx := bytes.IndexByte(p.buf.Bytes(), '\n')
+		if idx < 0 {
+			break
+		}
+		line := p.buf.Next(idx + 1)
+		p.writeLine(line)
+	}
+	return len(b), nil
+}
+
+// Flush writes any buffered partial line followed by a newline.
+func (p *prefixWriter) Flush() {
+	if p.buf.Len() == 0 {
+		return
+	}
+	line := append(p.buf.Bytes(), '\n')
+	p.buf.Reset()
+	p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = io.WriteString(p.w, p.prefix)
+	_, _ = p.w.Write(line)
+}
+
+func init() {
+	foreachCmd.Flags().IntVarP(&foreachParallel, "parallel", "p", runtime.NumCPU(), "maximum number of worktrees to run in at once")
+	foreachCmd.Flags().StringVar(&foreachFilter, "filter", "", "only run in worktrees whose branch matches this glob")
+	foreachCmd.Flags().BoolVar(&foreachIncludeMain, "include-main", false, "also run in the main worktree")
+	rootCmd.AddCommand(foreachCmd)
+}
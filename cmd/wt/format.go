@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/trungung/wt/internal/core"
+)
+
+var listFormat string
+
+// printFormatted renders format for every worktree, one line each.
+// See core.WorktreeInfo for the available fields.
+func printFormatted(out io.Writer, format string) error {
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+
+	infos, err := core.ListWorktreeInfos()
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if err := tmpl.Execute(out, info); err != nil {
+			return fmt.Errorf("--format failed for %s: %w", info.Path, err)
+		}
+		if _, err := fmt.Fprintln(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.Flags().StringVar(&listFormat, "format", "", "list worktrees using a Go template, e.g. '{{.Branch}} {{if .Dirty}}*{{end}}'")
+}
@@ -6,15 +6,99 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/trungung/wt/internal/core"
+	"github.com/trungung/wt/internal/ui"
 )
 
+var healthFix bool
+var healthYes bool
+
 var healthCmd = &cobra.Command{
 	Use:   "health",
 	Short: "check project health",
+	Long: `Check the configuration, the default branch, the worktree base and the
+worktrees themselves, and report each issue as OK, WARN or ERROR.
+
+With --fix, the issues that can be fixed automatically are listed with the
+change that fixes them, e.g. deleting unknown config keys or pruning the
+records of worktrees whose directory is gone. Nothing is changed unless the
+fixes are confirmed at the prompt or --yes is given; without a terminal,
+--fix only previews.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{jsonAnnotation: "health"},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if healthYes && !healthFix {
+			return fmt.Errorf("--yes can only be used with --fix")
+		}
+
 		checks, hasError := core.RunHealthCheck()
+		var fixable []core.HealthCheck
 		for _, c := range checks {
-			fmt.Printf("[%s] %s: %s\n", c.Level, c.Name, c.Message)
+			if c.Fix != "" {
+				fixable = append(fixable, c)
+			}
+		}
+
+		if !jsonOutput {
+			for _, c := range checks {
+				fmt.Printf("[%s] %s: %s\n", c.Level, c.Name, c.Message)
+			}
+			switch {
+			case !healthFix && len(fixable) > 0:
+				fmt.Printf("\n%d issues can be fixed automatically; run 'wt health --fix' to preview the fixes.\n", len(fixable))
+			case healthFix && len(fixable) == 0:
+				fmt.Println("\nNothing to fix automatically.")
+			case healthFix:
+				fmt.Println("\nFixes:")
+				for _, c := range fixable {
+					fmt.Printf("  - %s\n", c.Fix)
+				}
+			}
+		}
+
+		var fixes []healthFixJSON
+		if healthFix && len(fixable) > 0 {
+			apply := healthYes
+			if !apply && !jsonOutput && stdinIsTerminal() {
+				ok, err := ui.PromptBoolWithError(fmt.Sprintf("Apply these %d fixes?", len(fixable)), false)
+				apply = err == nil && ok
+			}
+
+			failed := false
+			for _, c := range fixable {
+				result := healthFixJSON{Check: c.Name, Fix: c.Fix}
+				if apply {
+					if err := c.ApplyFix(); err != nil {
+						failed = true
+						result.Error = err.Error()
+						if !jsonOutput {
+							fmt.Fprintf(os.Stderr, "failed: %s: %v\n", c.Fix, err)
+						}
+					} else {
+						result.Applied = true
+						if !jsonOutput {
+							fmt.Printf("fixed: %s\n", c.Fix)
+						}
+					}
+				}
+				fixes = append(fixes, result)
+			}
+
+			if apply {
+				// The exit code reflects the state after the fixes
+				_, hasError = core.RunHealthCheck()
+				hasError = hasError || failed
+			} else if !jsonOutput {
+				fmt.Println("\nDry run: nothing was changed. Run 'wt health --fix --yes' to apply the fixes.")
+			}
+		}
+
+		if jsonOutput {
+			if checks == nil {
+				checks = []core.HealthCheck{}
+			}
+			if err := printJSON(healthJSON{SchemaVersion: jsonSchemaVersion, OK: !hasError, Checks: checks, Fixes: fixes}); err != nil {
+				return err
+			}
 		}
 		if hasError {
 			os.Exit(1)
@@ -24,5 +108,7 @@ var healthCmd = &cobra.Command{
 }
 
 func init() {
+	healthCmd.Flags().BoolVar(&healthFix, "fix", false, "list automatic fixes and apply them after confirmation")
+	healthCmd.Flags().BoolVarP(&healthYes, "yes", "y", false, "with --fix, apply the fixes without asking")
 	rootCmd.AddCommand(healthCmd)
 }
@@ -1,6 +1,14 @@
 package main
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/trungung/wt/internal/config"
+	"github.com/trungung/wt/internal/core"
+)
 
 // splitPromptList splits a comma-separated string into a list of trimmed strings
 func splitPromptList(s string) []string {
@@ -17,3 +25,25 @@ func splitPromptList(s string) []string {
 	}
 	return result
 }
+
+// commandLines returns the display form of each configured command
+func commandLines(cmds []config.Command) []string {
+	lines := make([]string, 0, len(cmds))
+	for _, c := range cmds {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
+
+// handleEnsureError reports an EnsureWorktree failure. Rollback errors are
+// printed with their rollback status and terminate the process; any other
+// error is returned unchanged for cobra to report.
+func handleEnsureError(err error) error {
+	var rbErr *core.RollbackError
+	if errors.As(err, &rbErr) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", rbErr.OriginalErr)
+		fmt.Fprintf(os.Stderr, "Rollback status: %s\n", rbErr.RollbackStatus)
+		os.Exit(1)
+	}
+	return err
+}
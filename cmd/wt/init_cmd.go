@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
 	"github.com/trungung/wt/internal/config"
+	"github.com/trungung/wt/internal/core"
 	"github.com/trungung/wt/internal/git"
 )
 
@@ -28,7 +29,7 @@ var initCmd = &cobra.Command{
 			return nil
 		}
 
-		detected, err := git.GetDefaultBranch()
+		detected, _, err := core.DetectDefaultBranch(root, &config.Config{Remote: remoteFlag})
 		if err != nil && initYes {
 			return fmt.Errorf("could not auto-detect default branch for --yes: %w", err)
 		}
@@ -37,8 +38,9 @@ var initCmd = &cobra.Command{
 			DefaultBranch:            detected,
 			WorktreePathTemplate:     "$REPO_PATH.wt",
 			WorktreeCopyPatterns:     []string{},
-			PostCreateCmd:            []string{},
+			PostCreateCmd:            []config.Command{},
 			DeleteBranchWithWorktree: false,
+			Remote:                   remoteFlag,
 		}
 
 		if !initYes {
@@ -84,14 +86,14 @@ var initCmd = &cobra.Command{
 
 			err = huh.NewInput().
 				Title("Post-create commands (comma separated)").
-				Placeholder(strings.Join(cfg.PostCreateCmd, ", ")).
+				Placeholder(strings.Join(commandLines(cfg.PostCreateCmd), ", ")).
 				Value(&postCmds).
 				Run()
 			if err != nil {
 				return err
 			}
-			cfg.PostCreateCmd = splitPromptList(postCmds)
-			fmt.Printf("Post-create commands: [%s]\n\n", strings.Join(cfg.PostCreateCmd, ", "))
+			cfg.PostCreateCmd = config.Commands(splitPromptList(postCmds)...)
+			fmt.Printf("Post-create commands: [%s]\n\n", strings.Join(commandLines(cfg.PostCreateCmd), ", "))
 
 			err = huh.NewConfirm().
 				Title("Delete branch with worktree?").
@@ -107,6 +109,13 @@ var initCmd = &cobra.Command{
 			return err
 		}
 
+		// Commands entered here were just written by the user, so approve them
+		if cfg.HasCommands() {
+			if err := core.TrustConfig(&core.RepoEnv{Root: root, Config: cfg}); err != nil {
+				return fmt.Errorf("failed to record trust: %w", err)
+			}
+		}
+
 		fmt.Println(configPath)
 
 		if !initYes {
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/core"
+	"github.com/trungung/wt/internal/git"
+)
+
+// jsonSchemaVersion is the version of every --json document. It is bumped on
+// incompatible changes only; new fields may be added without a bump.
+// The schema is documented in docs/user/api-references/json-output.md.
+const jsonSchemaVersion = 1
+
+// jsonAnnotation marks commands that support --json
+const jsonAnnotation = "wt/json"
+
+var jsonOutput bool
+
+// listJSON is the document printed by `wt --json`
+type listJSON struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	Worktrees     []git.Worktree `json:"worktrees"`
+}
+
+// statusJSON is the document printed by `wt status --json`
+type statusJSON struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Worktrees     []worktreeStatusJ `json:"worktrees"`
+}
+
+// worktreeStatusJ is one worktree in statusJSON
+type worktreeStatusJ struct {
+	git.Worktree
+	IsMain         bool              `json:"isMain"`
+	LastCommit     *time.Time        `json:"lastCommit,omitempty"`
+	Dirty          bool              `json:"dirty"`
+	Changes        *git.StatusCounts `json:"changes,omitempty"`
+	Upstream       string            `json:"upstream,omitempty"`
+	AheadUpstream  int               `json:"aheadUpstream"`
+	BehindUpstream int               `json:"behindUpstream"`
+	DefaultBranch  string            `json:"defaultBranch,omitempty"`
+	AheadDefault   int               `json:"aheadDefault"`
+	BehindDefault  int               `json:"behindDefault"`
+	Merged         bool              `json:"merged"`
+	Error          string            `json:"error,omitempty"`
+}
+
+// healthJSON is the document printed by `wt health --json`
+type healthJSON struct {
+	SchemaVersion int                `json:"schemaVersion"`
+	OK            bool               `json:"ok"`
+	Checks        []core.HealthCheck `json:"checks"`
+	// Fixes is only set with --fix
+	Fixes []healthFixJSON `json:"fixes,omitempty"`
+}
+
+// healthFixJSON is a fix listed or applied by `wt health --fix --json`
+type healthFixJSON struct {
+	Check   string `json:"check"`
+	Fix     string `json:"fix"`
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+// pruneJSON is the document printed by `wt prune --json`
+type pruneJSON struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	DryRun        bool              `json:"dryRun"`
+	Pruned        []core.PruneEntry `json:"pruned"`
+	Skipped       []core.PruneEntry `json:"skipped"`
+}
+
+// newStatusJSON converts worktree statuses to their JSON form
+func newStatusJSON(statuses []core.WorktreeStatus) statusJSON {
+	doc := statusJSON{SchemaVersion: jsonSchemaVersion, Worktrees: []worktreeStatusJ{}}
+	for _, s := range statuses {
+		entry := worktreeStatusJ{Worktree: s.Worktree, IsMain: s.IsMain}
+		if s.Err != nil {
+			entry.Error = s.Err.Error()
+			doc.Worktrees = append(doc.Worktrees, entry)
+			continue
+		}
+		changes := s.Changes
+		if !s.LastCommit.IsZero() {
+			lastCommit := s.LastCommit
+			entry.LastCommit = &lastCommit
+		}
+		entry.Dirty = s.IsDirty()
+		entry.Changes = &changes
+		entry.Upstream = s.Upstream
+		entry.AheadUpstream, entry.BehindUpstream = s.AheadUpstream, s.BehindUpstream
+		entry.DefaultBranch = s.DefaultBranch
+		entry.AheadDefault, entry.BehindDefault = s.AheadDefault, s.BehindDefault
+		entry.Merged = s.Merged
+		doc.Worktrees = append(doc.Worktrees, entry)
+	}
+	return doc
+}
+
+// printJSON writes v as indented JSON to stdout
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// checkJSONSupport rejects --json for commands that have no JSON output
+func checkJSONSupport(cmd *cobra.Command) error {
+	if jsonOutput && cmd.Annotations[jsonAnnotation] == "" {
+		return fmt.Errorf("--json is not supported by 'wt %s'", cmd.Name())
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "print machine-readable JSON (wt, status, health, prune)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return checkJSONSupport(cmd)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/core"
+)
+
+var lockReason string
+
+var lockCmd = &cobra.Command{
+	Use:   "lock <branch|path|commit>",
+	Short: "lock a worktree so it is never pruned or removed",
+	Long: `Lock a worktree with 'git worktree lock', for example one on a removable
+drive or a long-running sandbox.
+
+Locked worktrees are skipped by 'wt prune' and refused by 'wt remove' unless
+--ignore-lock is given. The reason is shown by 'wt' and 'wt status'.
+
+Not to be confused with 'wt locks', which shows wt's own process locks.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		wt, err := core.LockWorktree(args[0], lockReason)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Locked %s\n", wt.Path)
+		return nil
+	},
+}
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock <branch|path|commit>",
+	Short: "unlock a worktree locked with wt lock",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		wt, err := core.UnlockWorktree(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Unlocked %s\n", wt.Path)
+		return nil
+	},
+}
+
+func init() {
+	lockCmd.Flags().StringVar(&lockReason, "reason", "", "why the worktree is locked")
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(unlockCmd)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/git"
+)
+
+var locksCmd = &cobra.Command{
+	Use:   "locks",
+	Short: "show the repository lock and worktree locks with their holders",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, err := git.GetRepoRoot()
+		if err != nil {
+			return err
+		}
+		statuses, err := git.ListLocks(root)
+		if err != nil {
+			return err
+		}
+		printLocks(os.Stdout, statuses)
+		return nil
+	},
+}
+
+// printLocks writes one row per lock: its name, state and recorded holder
+func printLocks(out io.Writer, statuses []git.LockStatus) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer func() {
+		_ = w.Flush()
+	}()
+
+	_, _ = fmt.Fprintln(w, "LOCK\tSTATE\tPID\tHOST\tSTARTED\tCOMMAND")
+	for _, s := range statuses {
+		if s.Info == nil {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\n", s.Name, lockState(s))
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", s.Name, lockState(s),
+			s.Info.PID, s.Info.Hostname, s.Info.Started.Format("2006-01-02 15:04:05"), s.Info.Command)
+	}
+	if len(statuses) > 0 {
+		_, _ = fmt.Fprintf(w, "\nRepository lock: %s\n", statuses[0].Path)
+	}
+}
+
+// lockState describes whether a lock is held and whether its owner info is stale
+func lockState(s git.LockStatus) string {
+	switch {
+	case s.Held && s.Info != nil && s.Info.IsStale():
+		return "held (owner gone)"
+	case s.Held:
+		return "held"
+	case s.Info != nil:
+		return "free (stale info)"
+	default:
+		return "free"
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(locksCmd)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/core"
+)
+
+var logsPathOnly bool
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <branch|path|commit>",
+	Short: "show the setup command output recorded when a worktree was created",
+	Long: `Show the output of the pre-create hook, postCreateCmd and post-create hook
+recorded the last time a worktree was created for <branch>. A worktree can
+also be given by directory name, path or commit, as for 'wt remove'.
+
+The log lives in the git directory (.git/wt/logs/<dir-name>/post-create.log)
+and is kept after a failed creation was rolled back.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := core.LogPath(args[0])
+		if err != nil {
+			return err
+		}
+		if logsPathOnly {
+			fmt.Println(path)
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("no post-create log for %q", args[0])
+			}
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		_, err = io.Copy(os.Stdout, f)
+		return err
+	},
+}
+
+func init() {
+	logsCmd.Flags().BoolVar(&logsPathOnly, "path", false, "print the log file path instead of its content")
+	rootCmd.AddCommand(logsCmd)
+}
@@ -1,9 +1,9 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/trungung/wt/internal/core"
@@ -13,6 +13,9 @@ import (
 var version = "0.0.5"
 
 var fromBase string
+var listLong bool
+var lockTimeout time.Duration
+var remoteFlag string
 
 var rootCmd = &cobra.Command{
 	Use:   "wt [branch]",
@@ -21,40 +24,83 @@ var rootCmd = &cobra.Command{
 
 Commands:
   wt                 List all worktrees
-  wt <branch>        Ensure worktree exists for branch (creates if needed)
+  wt status          Show dirty, ahead/behind, merged and age per worktree (also: wt --long)
+  wt <branch>        Ensure worktree exists for branch (creates if needed; wt <remote>/<branch> tracks a remote branch)
   wt cd <branch>     Create worktree and navigate to it (requires shell-setup)
+  wt run <branch>    Ensure worktree and run a command in it (wt run <branch> -- <cmd>)
+  wt foreach         Run a command in every worktree (wt foreach -- <cmd>)
+  wt logs <branch>   Show setup command output recorded at creation
   wt init            Create .wt.config.json
   wt remove <branch> Remove worktree
-  wt prune           Remove merged worktrees
+  wt mv <branch>     Move a worktree (wt mv --to-template moves all to the configured location)
+  wt rename <branch> Rename a branch and its worktree directory (wt rename <old> <new>)
+  wt lock <branch>   Protect a worktree from prune and remove (wt unlock reverts)
+  wt prune           Remove merged worktrees (--gone, --older-than, --inactive-for: also stale ones)
   wt health          Check configuration
+  wt repair          Reconnect worktrees after the repository was moved
+  wt locks           Show who holds the repository lock
+  wt trust           Approve commands in .wt.config.json (wt untrust revokes)
   wt shell-setup     Generate shell wrapper and completions
 `,
-	Version: version,
-	Args:    cobra.MaximumNArgs(1),
+	Version:     version,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{jsonAnnotation: "list"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
+			if listFormat != "" {
+				if listLong || jsonOutput {
+					return fmt.Errorf("--format cannot be combined with --long or --json")
+				}
+				// wt --format: custom listing
+				return printFormatted(os.Stdout, listFormat)
+			}
+			if listLong {
+				// wt --long: list worktrees with status
+				statuses, err := core.GetWorktreeStatuses()
+				if err != nil {
+					return err
+				}
+				if jsonOutput {
+					return printJSON(newStatusJSON(statuses))
+				}
+				printStatusTable(os.Stdout, statuses, time.Now())
+				return nil
+			}
+
 			// wt: list worktrees
 			worktrees, err := git.ListWorktrees()
 			if err != nil {
 				return err
 			}
+			if jsonOutput {
+				if worktrees == nil {
+					worktrees = []git.Worktree{}
+				}
+				return printJSON(listJSON{SchemaVersion: jsonSchemaVersion, Worktrees: worktrees})
+			}
 			for _, wt := range worktrees {
+				if wt.Locked {
+					// Third column only for locked worktrees, so cut -f1/-f2 keep working
+					fmt.Printf("%s\t%s\t%s\n", wt.Branch, wt.Path, core.DescribeLock(wt))
+					continue
+				}
 				fmt.Printf("%s\t%s\n", wt.Branch, wt.Path)
 			}
 			return nil
 		}
 
+		if listLong || listFormat != "" {
+			return fmt.Errorf("--long and --format can only be used when listing worktrees")
+		}
+		if jsonOutput {
+			return fmt.Errorf("--json can only be used when listing worktrees")
+		}
+
 		// wt <branch>: ensure worktree
 		branch := args[0]
 		path, err := core.EnsureWorktree(branch, fromBase)
 		if err != nil {
-			var rbErr *core.RollbackError
-			if errors.As(err, &rbErr) {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", rbErr.OriginalErr)
-				fmt.Fprintf(os.Stderr, "Rollback status: %s\n", rbErr.RollbackStatus)
-				os.Exit(1)
-			}
-			return err
+			return handleEnsureError(err)
 		}
 		fmt.Println(path)
 		return nil
@@ -63,6 +109,13 @@ Commands:
 
 func init() {
 	rootCmd.Flags().StringVarP(&fromBase, "from", "f", "", "base branch to create from")
+	rootCmd.Flags().BoolVarP(&listLong, "long", "l", false, "list worktrees with status (same as wt status)")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 0, "how long to wait for a lock held by another wt process (default: lockTimeout config or 5s)")
+	rootCmd.PersistentFlags().StringVar(&remoteFlag, "remote", "", "remote holding the canonical branches (default: remote config or origin)")
+	cobra.OnInitialize(func() {
+		core.SetLockTimeout(lockTimeout)
+		core.SetRemote(remoteFlag)
+	})
 }
 
 func main() {
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/core"
+)
+
+var mvToTemplate bool
+var mvForce bool
+var mvIgnoreLock bool
+var mvDryRun bool
+
+var mvCmd = &cobra.Command{
+	Use:   "mv <branch|path|commit> [new-path]",
+	Short: "move a worktree, or all worktrees to the configured location",
+	Long: `Move a worktree with 'git worktree move' and print its new path.
+
+Without [new-path], the worktree is moved to the location worktreePathTemplate
+computes for it. If [new-path] is an existing directory, the worktree is moved
+into it.
+
+With --to-template, every linked worktree that is not where the current
+worktreePathTemplate places it is moved there, e.g. after changing the
+template in .wt.config.json.
+
+Moves to another filesystem copy the worktree and then delete the original;
+dirty worktrees are refused unless --force is given. Locked worktrees are
+refused unless --ignore-lock is given.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if mvToTemplate {
+			if len(args) > 0 {
+				return fmt.Errorf("--to-template does not take arguments")
+			}
+			return nil
+		}
+		if mvDryRun {
+			return fmt.Errorf("--dry-run can only be used with --to-template")
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := core.MoveOptions{
+			Force:      mvForce,
+			IgnoreLock: mvIgnoreLock,
+			DryRun:     mvDryRun,
+		}
+
+		if !mvToTemplate {
+			newPath := ""
+			if len(args) == 2 {
+				newPath = args[1]
+			}
+			path, err := core.MoveWorktree(args[0], newPath, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+			return nil
+		}
+
+		result, err := core.MoveToTemplate(opts)
+		if err != nil {
+			return err
+		}
+		for _, e := range result.Moved {
+			fmt.Printf("%s: %s -> %s\n", e.Branch, e.From, e.To)
+		}
+		for _, e := range result.Skipped {
+			fmt.Printf("%s: skipped: %s\n", e.Branch, e.Reason)
+		}
+		switch {
+		case len(result.Moved) == 0 && len(result.Skipped) == 0:
+			fmt.Println("All worktrees are at their configured location.")
+		case mvDryRun:
+			fmt.Printf("\nWould move %d worktrees (run without --dry-run to move).\n", len(result.Moved))
+		default:
+			fmt.Printf("Moved %d worktrees.\n", len(result.Moved))
+		}
+		return nil
+	},
+}
+
+func init() {
+	mvCmd.Flags().BoolVar(&mvToTemplate, "to-template", false, "move every worktree to the location worktreePathTemplate computes")
+	mvCmd.Flags().BoolVarP(&mvForce, "force", "f", false, "move dirty worktrees to another filesystem")
+	mvCmd.Flags().BoolVar(&mvIgnoreLock, "ignore-lock", false, "move locked worktrees too (see wt lock)")
+	mvCmd.Flags().BoolVar(&mvDryRun, "dry-run", false, "with --to-template, show what would be moved")
+	rootCmd.AddCommand(mvCmd)
+}
@@ -2,50 +2,151 @@ package main
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/config"
 	"github.com/trungung/wt/internal/core"
 )
 
 var pruneForce bool
 var pruneDryRun bool
 var pruneFetch bool
+var pruneIgnoreLock bool
+var pruneStrategy string
+var pruneGone bool
+var pruneOlderThan string
+var pruneInactiveFor string
+var pruneDeleteUnmerged bool
 
 var pruneCmd = &cobra.Command{
-	Use:   "prune",
-	Short: "remove worktrees whose branches are merged into default branch",
+	Use:         "prune",
+	Short:       "remove worktrees whose branches are merged into default branch (or gone or stale)",
+	Annotations: map[string]string{jsonAnnotation: "prune"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		opts := core.PruneOptions{
-			DryRun: pruneDryRun,
-			Force:  pruneForce,
-			Fetch:  pruneFetch,
+			DryRun:         pruneDryRun,
+			Force:          pruneForce,
+			Fetch:          pruneFetch,
+			IgnoreLock:     pruneIgnoreLock,
+			Strategy:       pruneStrategy,
+			DeleteUnmerged: pruneDeleteUnmerged,
+		}
+		// --gone=false overrides pruneGone in the config
+		if cmd.Flags().Changed("gone") {
+			opts.Gone = &pruneGone
+		}
+		var err error
+		if pruneOlderThan != "" {
+			if opts.OlderThan, err = core.ParseAge(pruneOlderThan); err != nil {
+				return fmt.Errorf("--older-than: %w", err)
+			}
+		}
+		if pruneInactiveFor != "" {
+			if opts.InactiveFor, err = core.ParseAge(pruneInactiveFor); err != nil {
+				return fmt.Errorf("--inactive-for: %w", err)
+			}
 		}
 
-		count, candidates, err := core.PruneWorktrees(opts)
+		result, err := core.PruneWorktrees(opts)
 		if err != nil {
 			return err
 		}
 
+		if jsonOutput {
+			doc := pruneJSON{
+				SchemaVersion: jsonSchemaVersion,
+				DryRun:        pruneDryRun,
+				Pruned:        append([]core.PruneEntry{}, result.Pruned...),
+				Skipped:       append([]core.PruneEntry{}, result.Skipped...),
+			}
+			return printJSON(doc)
+		}
+
+		now := time.Now()
+		if len(result.Pruned) == 0 && len(result.Skipped) == 0 {
+			fmt.Println("No worktrees to prune.")
+			return nil
+		}
 		if pruneDryRun {
-			if len(candidates) == 0 {
-				fmt.Println("No worktrees to prune.")
-				return nil
+			if len(result.Pruned) > 0 {
+				fmt.Println("Candidates for pruning:")
+				for _, e := range result.Pruned {
+					fmt.Printf("  %s (%s)\n", e.Branch, describePruneEntry(e, now))
+				}
 			}
-			fmt.Println("Candidates for pruning:")
-			for _, b := range candidates {
-				fmt.Printf("  %s\n", b)
+			if len(result.Skipped) > 0 {
+				fmt.Println("Skipped:")
+				for _, e := range result.Skipped {
+					fmt.Printf("  %s\n", describePruneSkip(e, now))
+				}
 			}
-			fmt.Printf("\nTotal candidates: %d (run without --dry-run to prune)\n", len(candidates))
-		} else {
-			fmt.Printf("Pruned %d worktrees.\n", count)
+			fmt.Printf("\nTotal candidates: %d (run without --dry-run to prune)\n", len(result.Pruned))
+			return nil
 		}
+
+		for _, e := range result.Pruned {
+			fmt.Printf("Pruned %s (%s)\n", e.Branch, describePruneEntry(e, now))
+		}
+		for _, e := range result.Skipped {
+			fmt.Printf("Skipped %s\n", describePruneSkip(e, now))
+		}
+		fmt.Printf("\nPruned %d worktrees.\n", len(result.Pruned))
 		return nil
 	},
 }
 
+// describePruneEntry renders the rule that selected a worktree, for the age
+// rules the timestamps it was judged by and whether its branch is kept, e.g.
+// "inactive: last commit 40d ago, modified 21d ago, never accessed"
+func describePruneEntry(e core.PruneEntry, now time.Time) string {
+	desc := describePruneRule(e, now)
+	if e.BranchKept {
+		desc += "; branch kept, use --delete-unmerged to delete it"
+	}
+	return desc
+}
+
+func describePruneRule(e core.PruneEntry, now time.Time) string {
+	ago := func(label string, t time.Time) string {
+		if t.IsZero() {
+			return "never " + label
+		}
+		return fmt.Sprintf("%s %s ago", label, formatAge(now.Sub(t)))
+	}
+	switch e.Rule {
+	case core.PruneRuleOlderThan:
+		return fmt.Sprintf("%s: %s", e.Rule, ago("last commit", e.LastCommit))
+	case core.PruneRuleInactive:
+		return fmt.Sprintf("%s: %s, %s, %s", e.Rule, ago("last commit", e.LastCommit), ago("modified", e.LastModified), ago("accessed", e.LastAccess))
+	}
+	return e.Rule
+}
+
+// describePruneSkip renders a skipped worktree with the reason and, where
+// a flag overrides it, the hint, e.g. "feature/x (merged): dirty (use --force to prune)"
+func describePruneSkip(e core.PruneEntry, now time.Time) string {
+	hint := ""
+	switch {
+	case e.Reason == "dirty":
+		hint = " (use --force to prune)"
+	case strings.HasPrefix(e.Reason, "locked"):
+		hint = " (use --ignore-lock to prune)"
+	}
+	return fmt.Sprintf("%s (%s): %s%s", e.Branch, describePruneEntry(e, now), e.Reason, hint)
+}
+
 func init() {
-	pruneCmd.Flags().BoolVarP(&pruneForce, "force", "f", false, "force removal even if dirty")
+	pruneCmd.Flags().BoolVarP(&pruneForce, "force", "f", false, "force removal even if dirty (uncommitted changes are lost)")
 	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "show what would be removed")
-	pruneCmd.Flags().BoolVar(&pruneFetch, "fetch", false, "run git fetch --prune first")
+	pruneCmd.Flags().BoolVar(&pruneFetch, "fetch", false, "run git fetch --prune for the remote first")
+	pruneCmd.Flags().BoolVar(&pruneIgnoreLock, "ignore-lock", false, "also prune locked worktrees (see wt lock)")
+	pruneCmd.Flags().StringVar(&pruneStrategy, "strategy", "", "how to find merged branches: "+strings.Join(config.PruneStrategies, ", ")+" (default from config, else ancestry)")
+	pruneCmd.Flags().BoolVar(&pruneGone, "gone", false, "also prune branches whose upstream was deleted (default from config)")
+	pruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", "also prune branches whose last commit is older than this (e.g. 14d, 2w, 36h)")
+	pruneCmd.Flags().StringVar(&pruneInactiveFor, "inactive-for", "", "also prune worktrees without commits, file changes or wt access for this long (e.g. 7d)")
+	pruneCmd.Flags().BoolVar(&pruneDeleteUnmerged, "delete-unmerged", false, "with deleteBranchWithWorktree, also delete branches that are not merged (e.g. gone or stale ones)")
+	_ = pruneCmd.RegisterFlagCompletionFunc("strategy", cobra.FixedCompletions(config.PruneStrategies, cobra.ShellCompDirectiveNoFileComp))
 	rootCmd.AddCommand(pruneCmd)
 }
@@ -11,13 +11,19 @@ import (
 )
 
 var forceRemove bool
+var removeIgnoreLock bool
 
 var removeCmd = &cobra.Command{
-	Use:   "remove [branch]",
+	Use:   "remove [branch|path|commit]",
 	Short: "remove a worktree and optionally delete its branch",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Remove a worktree and optionally delete its branch.
+
+The worktree is given by branch name, directory name, path or a commit id
+prefix (at least 4 characters), so detached and prunable worktrees can be
+removed too. Without an argument, a worktree is selected interactively.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var branch string
+		var target string
 		if len(args) == 0 {
 			// Interactive selection
 			worktrees, err := git.ListWorktrees()
@@ -28,40 +34,29 @@ var removeCmd = &cobra.Command{
 				return fmt.Errorf("no other worktrees to remove")
 			}
 
-			var branches []string
+			// Select by path: detached worktrees share the same branch label
+			var options []huh.Option[string]
 			for i, wt := range worktrees {
 				if i == 0 {
 					continue // Skip main worktree
 				}
-				branches = append(branches, wt.Branch)
+				options = append(options, huh.NewOption(fmt.Sprintf("%s  %s", wt.Branch, wt.Path), wt.Path))
 			}
 
 			err = huh.NewSelect[string]().
 				Title("Select a worktree to remove").
-				Options(huh.NewOptions(branches...)...).
-				Value(&branch).
+				Options(options...).
+				Value(&target).
 				Run()
 			if err != nil {
 				return err
 			}
 
-			if branch == "" {
+			if target == "" {
 				return fmt.Errorf("no worktree selected")
 			}
-
-			// Validate that the returned branch actually exists as a worktree
-			found := false
-			for _, b := range branches {
-				if b == branch {
-					found = true
-					break
-				}
-			}
-			if !found {
-				return fmt.Errorf("invalid worktree selected: %s", branch)
-			}
 		} else {
-			branch = args[0]
+			target = args[0]
 		}
 
 		confirmFn := func(msg string) bool {
@@ -73,11 +68,16 @@ var removeCmd = &cobra.Command{
 			return result
 		}
 
-		return core.RemoveWorktree(branch, forceRemove, confirmFn)
+		return core.RemoveWorktree(target, core.RemoveOptions{
+			Force:      forceRemove,
+			IgnoreLock: removeIgnoreLock,
+			Confirm:    confirmFn,
+		})
 	},
 }
 
 func init() {
 	removeCmd.Flags().BoolVarP(&forceRemove, "force", "f", false, "force removal even if dirty")
+	removeCmd.Flags().BoolVar(&removeIgnoreLock, "ignore-lock", false, "remove even if the worktree is locked (see wt lock)")
 	rootCmd.AddCommand(removeCmd)
 }
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/core"
+)
+
+var renameIgnoreLock bool
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <branch|path|commit> <new-branch>",
+	Short: "rename a worktree's branch and directory together",
+	Long: `Rename the branch checked out in a worktree and move the worktree to the
+directory name the new branch maps to, then print its new path. The worktree
+stays in the same parent directory.
+
+The new name is checked for collisions like 'wt <branch>' does. An upstream
+that tracked the remote branch of the old name is pointed at the new name, so
+the next push publishes the renamed branch. If the worktree cannot be moved,
+the branch is renamed back.
+
+Locked worktrees are refused unless --ignore-lock is given.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := core.RenameWorktree(args[0], args[1], core.MoveOptions{IgnoreLock: renameIgnoreLock})
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+func init() {
+	renameCmd.Flags().BoolVar(&renameIgnoreLock, "ignore-lock", false, "rename locked worktrees too (see wt lock)")
+	rootCmd.AddCommand(renameCmd)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/core"
+)
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "reconnect worktrees after the repository or worktrees were moved",
+	Long: `Reconnect worktrees whose links to the repository broke, e.g. because the
+repository directory or the worktree base was moved or renamed.
+
+Runs 'git worktree repair' on every registered worktree directory and every
+directory in the worktree base that belongs to this repository, then prunes
+the records of worktrees whose directory is gone (locked worktrees are kept).
+Each fix is reported. Run it from the main worktree: linked worktrees cannot
+find the repository until they are repaired.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := core.RepairWorktrees()
+		if err != nil {
+			return err
+		}
+		for _, f := range result.Repaired {
+			fmt.Printf("repaired %s: %s\n", f.Path, f.Message)
+		}
+		for _, f := range result.Pruned {
+			fmt.Printf("pruned %s: %s\n", f.Path, f.Message)
+		}
+		for _, f := range result.Failed {
+			fmt.Fprintf(os.Stderr, "failed %s: %s\n", f.Path, f.Message)
+		}
+		if len(result.Repaired)+len(result.Pruned)+len(result.Failed) == 0 {
+			fmt.Println("Nothing to repair.")
+		}
+		if len(result.Failed) > 0 {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/core"
+)
+
+var runFromBase string
+
+var runCmd = &cobra.Command{
+	Use:   "run <branch> -- <command> [args...]",
+	Short: "ensure a worktree exists and run a command inside it",
+	Long: `Ensures a worktree exists for the given branch (creating it if needed),
+then runs the command with the worktree as its working directory.
+
+stdin, stdout and stderr are passed through, signals are forwarded to the
+command, and wt exits with the command's exit code.
+
+Examples:
+  wt run feature/test -- npm test
+  wt run feature/new --from develop -- make build`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.ArgsLenAtDash() != 1 {
+			return fmt.Errorf("usage: wt run <branch> -- <command> [args...]")
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("missing command after --")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		branch := args[0]
+		command := args[1:]
+
+		path, err := core.EnsureWorktree(branch, runFromBase)
+		if err != nil {
+			return handleEnsureError(err)
+		}
+
+		code, err := runInDir(path, command)
+		if err != nil {
+			return err
+		}
+		os.Exit(code)
+		return nil
+	},
+}
+
+// runInDir runs argv in dir with the standard streams attached, forwarding
+// signals received by wt to the child. It returns the child's exit code.
+func runInDir(dir string, argv []string) (int, error) {
+	c := exec.Command(argv[0], argv[1:]...)
+	c.Dir = dir
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	defer signal.Stop(sigCh)
+
+	if err := c.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start %s: %w", argv[0], err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				_ = c.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := c.Wait()
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal()), nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	return 0, err
+}
+
+func init() {
+	runCmd.Flags().StringVarP(&runFromBase, "from", "f", "", "base branch to create from")
+	rootCmd.AddCommand(runCmd)
+}
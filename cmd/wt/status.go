@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/core"
+	"github.com/trungung/wt/internal/git"
+)
+
+var statusCmd = &cobra.Command{
+	Use:         "status",
+	Short:       "show dirty, ahead/behind, merged and age information for every worktree",
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{jsonAnnotation: "status"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statuses, err := core.GetWorktreeStatuses()
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(newStatusJSON(statuses))
+		}
+		printStatusTable(os.Stdout, statuses, time.Now())
+		return nil
+	},
+}
+
+// printStatusTable renders worktree statuses as an aligned table.
+func printStatusTable(w io.Writer, statuses []core.WorktreeStatus, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BRANCH\tHEAD\tCHANGES\tUPSTREAM\tDEFAULT\tMERGED\tLOCKED\tLAST COMMIT\tPATH")
+	for _, s := range statuses {
+		if s.Err != nil {
+			head := "-"
+			if len(s.Worktree.Head) >= 7 {
+				head = s.Worktree.Head[:7]
+			}
+			fmt.Fprintf(tw, "%s\t%s\terror: %v\t-\t-\t-\t%s\t-\t%s\n",
+				s.Worktree.Branch, head, s.Err, formatLocked(s.Worktree), s.Worktree.Path)
+			continue
+		}
+
+		head := s.Head
+		if len(head) > 7 {
+			head = head[:7]
+		}
+
+		upstream := "-"
+		if s.Upstream != "" {
+			upstream = formatAheadBehind(s.AheadUpstream, s.BehindUpstream)
+		}
+
+		vsDefault := "-"
+		if s.DefaultBranch != "" {
+			vsDefault = formatAheadBehind(s.AheadDefault, s.BehindDefault)
+		}
+
+		merged := ""
+		if s.Merged {
+			merged = "yes"
+		}
+
+		lastCommit := "-"
+		if !s.LastCommit.IsZero() {
+			lastCommit = fmt.Sprintf("%s (%s)", s.LastCommit.Format("2006-01-02"), formatAge(now.Sub(s.LastCommit)))
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Worktree.Branch, head, formatChanges(s), upstream, vsDefault, merged, formatLocked(s.Worktree), lastCommit, s.Worktree.Path)
+	}
+	_ = tw.Flush()
+}
+
+// formatLocked renders the lock state: the reason, "yes" if none was given,
+// or empty if the worktree is not locked.
+func formatLocked(wt git.Worktree) string {
+	switch {
+	case !wt.Locked:
+		return ""
+	case wt.LockReason != "":
+		return wt.LockReason
+	default:
+		return "yes"
+	}
+}
+
+// formatChanges renders dirty file counts, e.g. "+1 ~2 ?3" or "clean".
+func formatChanges(s core.WorktreeStatus) string {
+	if !s.IsDirty() {
+		return "clean"
+	}
+	var parts []string
+	if s.Changes.Staged > 0 {
+		parts = append(parts, fmt.Sprintf("+%d", s.Changes.Staged))
+	}
+	if s.Changes.Modified > 0 {
+		parts = append(parts, fmt.Sprintf("~%d", s.Changes.Modified))
+	}
+	if s.Changes.Untracked > 0 {
+		parts = append(parts, fmt.Sprintf("?%d", s.Changes.Untracked))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatAheadBehind renders commit counts as "↑ahead ↓behind".
+func formatAheadBehind(ahead, behind int) string {
+	return fmt.Sprintf("↑%d ↓%d", ahead, behind)
+}
+
+// formatAge renders a duration in the largest whole unit (m, h, d).
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+	"github.com/trungung/wt/internal/config"
+	"github.com/trungung/wt/internal/core"
+	"github.com/trungung/wt/internal/ui"
+)
+
+var trustCommands bool
+
+var trustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "approve the commands in .wt.config.json",
+	Long: `Approve the commands (postCreateCmd and hooks) defined in .wt.config.json.
+
+wt never runs repository-defined commands until they are approved. Approval is
+stored as a hash of the commands in the user's state directory, so any change
+to them requires running 'wt trust' again.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		env, err := core.LoadRepoEnv()
+		if err != nil {
+			return err
+		}
+
+		commands := core.ConfigCommands(env.Config)
+		if len(commands) == 0 {
+			fmt.Fprintln(os.Stderr, "No commands defined; nothing to trust.")
+			return nil
+		}
+
+		printTrustCommands(config.GetConfigPath(env.Root), commands)
+		if err := core.TrustConfig(env); err != nil {
+			return fmt.Errorf("failed to record trust: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "Trusted.")
+		return nil
+	},
+}
+
+var untrustCmd = &cobra.Command{
+	Use:   "untrust",
+	Short: "revoke approval of the commands in .wt.config.json",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		env, err := core.LoadRepoEnv()
+		if err != nil {
+			return err
+		}
+		if err := core.UntrustConfig(env); err != nil {
+			return fmt.Errorf("failed to revoke trust: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Revoked trust for %s\n", config.GetConfigPath(env.Root))
+		return nil
+	},
+}
+
+// printTrustCommands lists the commands of a config on stderr
+func printTrustCommands(configPath string, commands []string) {
+	fmt.Fprintf(os.Stderr, "%s defines these commands:\n", configPath)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", c)
+	}
+}
+
+// promptTrust asks the user to approve untrusted commands. Without an
+// interactive terminal it declines, so scripts fail instead of hanging.
+func promptTrust(configPath string, commands []string) bool {
+	if !stdinIsTerminal() {
+		return false
+	}
+	printTrustCommands(configPath, commands)
+	ok, err := ui.PromptBoolStderr("Trust and run these commands?", false)
+	return err == nil && ok
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&trustCommands, "trust", false, "run repository-defined commands without approval (for CI)")
+	cobra.OnInitialize(func() {
+		core.SetTrustAll(trustCommands)
+		core.SetTrustPrompt(promptTrust)
+	})
+
+	rootCmd.AddCommand(trustCmd)
+	rootCmd.AddCommand(untrustCmd)
+}
+
+// stdinIsTerminal returns true if prompts can be answered interactively.
+// A character device is not enough: /dev/null is one, too.
+func stdinIsTerminal() bool {
+	return term.IsTerminal(os.Stdin.Fd())
+}
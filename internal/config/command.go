@@ -0,0 +1,205 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Command is a single entry of postCreateCmd or a hook list.
+// In JSON it is either a plain string, split into arguments with shell-word
+// quoting rules, or an object:
+//
+//	{"argv": ["npm", "run", "build all"], "env": {"CI": "1"}, "dir": "web", "timeout": "5m"}
+//
+// Commands are executed directly, never through a shell.
+type Command struct {
+	// Line is the string form; empty for structured entries
+	Line string
+
+	// Argv is set for structured entries only
+	Argv []string
+	// Env holds extra environment variables for the command
+	Env map[string]string
+	// Dir is the working directory relative to the worktree
+	Dir string
+	// Timeout is a Go duration string such as "30s" or "5m"
+	Timeout string
+}
+
+// structuredCommand is the JSON object form of a Command
+type structuredCommand struct {
+	Argv    []string          `json:"argv"`
+	Env     map[string]string `json:"env,omitempty"`
+	Dir     string            `json:"dir,omitempty"`
+	Timeout string            `json:"timeout,omitempty"`
+}
+
+// UnmarshalJSON accepts either a string or a structured command object
+func (c *Command) UnmarshalJSON(data []byte) error {
+	var line string
+	if err := json.Unmarshal(data, &line); err == nil {
+		*c = Command{Line: line}
+		return nil
+	}
+
+	var sc structuredCommand
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sc); err != nil {
+		return fmt.Errorf("command must be a string or an object with argv, env, dir and timeout: %w", err)
+	}
+	if len(sc.Argv) == 0 || sc.Argv[0] == "" {
+		return fmt.Errorf("command object requires a non-empty argv")
+	}
+	if sc.Timeout != "" {
+		if _, err := time.ParseDuration(sc.Timeout); err != nil {
+			return fmt.Errorf("invalid command timeout %q: %w", sc.Timeout, err)
+		}
+	}
+	*c = Command{Argv: sc.Argv, Env: sc.Env, Dir: sc.Dir, Timeout: sc.Timeout}
+	return nil
+}
+
+// MarshalJSON writes string commands back as strings
+func (c Command) MarshalJSON() ([]byte, error) {
+	if c.Argv == nil {
+		return json.Marshal(c.Line)
+	}
+	return json.Marshal(structuredCommand{Argv: c.Argv, Env: c.Env, Dir: c.Dir, Timeout: c.Timeout})
+}
+
+// IsEmpty returns true for a string command that contains only whitespace
+func (c Command) IsEmpty() bool {
+	return c.Argv == nil && strings.TrimSpace(c.Line) == ""
+}
+
+// Args returns the argument vector: Argv as-is for structured commands,
+// otherwise Line split with shell-word quoting rules.
+func (c Command) Args() ([]string, error) {
+	if c.Argv != nil {
+		return c.Argv, nil
+	}
+	return SplitArgs(c.Line)
+}
+
+// TimeoutDuration returns the parsed timeout, or 0 if none is set
+func (c Command) TimeoutDuration() (time.Duration, error) {
+	if c.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Timeout)
+}
+
+// String returns a human-readable form for messages
+func (c Command) String() string {
+	if c.Argv == nil {
+		return c.Line
+	}
+	quoted := make([]string, len(c.Argv))
+	for i, a := range c.Argv {
+		if a == "" || strings.ContainsAny(a, " \t\n'\"\\") {
+			quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		} else {
+			quoted[i] = a
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Commands wraps plain strings as string commands
+func Commands(lines ...string) []Command {
+	cmds := make([]Command, 0, len(lines))
+	for _, l := range lines {
+		cmds = append(cmds, Command{Line: l})
+	}
+	return cmds
+}
+
+// SplitArgs splits a command line into arguments using POSIX shell-word rules:
+// whitespace separates words, single quotes preserve everything literally,
+// double quotes allow \" \\ \$ and \` escapes, and a backslash outside quotes
+// escapes the next character.
+//
+// Commands are not run through a shell, so unquoted shell operators
+// (| & ; < > ( ) ` and $) are rejected instead of being passed on silently.
+func SplitArgs(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inWord := false
+
+	const (
+		none = iota
+		single
+		double
+	)
+	quote := none
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch quote {
+		case single:
+			if r == '\'' {
+				quote = none
+			} else {
+				cur.WriteRune(r)
+			}
+			continue
+		case double:
+			switch r {
+			case '"':
+				quote = none
+			case '\\':
+				if i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+					cur.WriteRune(runes[i])
+				} else {
+					cur.WriteRune(r)
+				}
+			case '$', '`':
+				return nil, fmt.Errorf("unescaped %q in double quotes: variables and command substitution are not supported", r)
+			default:
+				cur.WriteRune(r)
+			}
+			continue
+		}
+
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		case r == '\'':
+			quote = single
+			inWord = true
+		case r == '"':
+			quote = double
+			inWord = true
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			cur.WriteRune(runes[i])
+			inWord = true
+		case strings.ContainsRune("|&;<>()`$", r):
+			return nil, fmt.Errorf("shell operator %q is not supported (commands are not run through a shell); quote it to pass it literally", r)
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != none {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
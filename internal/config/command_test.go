@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "simple", input: "npm install", expected: []string{"npm", "install"}},
+		{name: "extra whitespace", input: "  make \t deps  ", expected: []string{"make", "deps"}},
+		{name: "empty", input: "   ", expected: nil},
+		{name: "double quotes", input: `npm run "build all"`, expected: []string{"npm", "run", "build all"}},
+		{name: "single quotes", input: `echo 'a | b; $HOME'`, expected: []string{"echo", "a | b; $HOME"}},
+		{name: "escapes in double quotes", input: `echo "say \"hi\" \$5"`, expected: []string{"echo", `say "hi" $5`}},
+		{name: "backslash outside quotes", input: `touch my\ file`, expected: []string{"touch", "my file"}},
+		{name: "adjacent quoted parts", input: `echo a"b c"'d'`, expected: []string{"echo", "ab cd"}},
+		{name: "empty quoted argument", input: `printf ''`, expected: []string{"printf", ""}},
+		{name: "path traversal is just an argument", input: "cp ../shared/.env .", expected: []string{"cp", "../shared/.env", "."}},
+		{name: "unterminated quote", input: `echo "oops`, wantErr: true},
+		{name: "trailing backslash", input: `echo \`, wantErr: true},
+		{name: "unquoted pipe", input: "cat a | grep b", wantErr: true},
+		{name: "unquoted chaining", input: "make && make install", wantErr: true},
+		{name: "unquoted variable", input: "echo $HOME", wantErr: true},
+		{name: "variable in double quotes", input: `echo "$HOME"`, wantErr: true},
+		{name: "command substitution", input: "echo `id`", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitArgs(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitArgs(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SplitArgs(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCommandJSON(t *testing.T) {
+	input := `[
+		"npm install",
+		{"argv": ["npm", "run", "build all"], "env": {"CI": "1"}, "dir": "web", "timeout": "5m"}
+	]`
+
+	var cmds []Command
+	if err := json.Unmarshal([]byte(input), &cmds); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if len(cmds) != 2 {
+		t.Fatalf("expected 2 commands, got %d", len(cmds))
+	}
+
+	args, err := cmds[0].Args()
+	if err != nil || !reflect.DeepEqual(args, []string{"npm", "install"}) {
+		t.Errorf("string command Args() = %q, %v", args, err)
+	}
+
+	structured := cmds[1]
+	args, err = structured.Args()
+	if err != nil || !reflect.DeepEqual(args, []string{"npm", "run", "build all"}) {
+		t.Errorf("structured command Args() = %q, %v", args, err)
+	}
+	if structured.Env["CI"] != "1" || structured.Dir != "web" {
+		t.Errorf("unexpected structured command: %+v", structured)
+	}
+	if d, err := structured.TimeoutDuration(); err != nil || d.Minutes() != 5 {
+		t.Errorf("TimeoutDuration() = %v, %v", d, err)
+	}
+
+	// Round trip keeps the string form for string commands
+	data, err := json.Marshal(cmds)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var again []Command
+	if err := json.Unmarshal(data, &again); err != nil {
+		t.Fatalf("unmarshal of %s failed: %v", data, err)
+	}
+	if !reflect.DeepEqual(cmds, again) {
+		t.Errorf("round trip mismatch: %+v vs %+v", cmds, again)
+	}
+}
+
+func TestCommandJSON_Invalid(t *testing.T) {
+	inputs := []string{
+		`42`,
+		`{"argv": []}`,
+		`{"command": "npm install"}`,
+		`{"argv": ["sleep", "1"], "timeout": "soon"}`,
+	}
+	for _, in := range inputs {
+		var c Command
+		if err := json.Unmarshal([]byte(in), &c); err == nil {
+			t.Errorf("expected error for %s, got %+v", in, c)
+		}
+	}
+}
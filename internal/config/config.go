@@ -2,17 +2,94 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	DefaultBranch            string   `json:"defaultBranch"`
-	WorktreePathTemplate     string   `json:"worktreePathTemplate"`
-	WorktreeCopyPatterns     []string `json:"worktreeCopyPatterns"`
-	PostCreateCmd            []string `json:"postCreateCmd"`
-	DeleteBranchWithWorktree bool     `json:"deleteBranchWithWorktree"`
+	DefaultBranch            string    `json:"defaultBranch"`
+	WorktreePathTemplate     string    `json:"worktreePathTemplate"`
+	WorktreeCopyPatterns     []string  `json:"worktreeCopyPatterns"`
+	PostCreateCmd            []Command `json:"postCreateCmd"`
+	DeleteBranchWithWorktree bool      `json:"deleteBranchWithWorktree"`
+	Hooks                    Hooks     `json:"hooks,omitzero"`
+	LockTimeout              string    `json:"lockTimeout,omitempty"`
+	Remote                   string    `json:"remote,omitempty"`
+	PruneStrategy            string    `json:"pruneStrategy,omitempty"`
+	PruneGone                bool      `json:"pruneGone,omitempty"`
+}
+
+// DefaultRemote is the remote used when the config does not name one
+const DefaultRemote = "origin"
+
+// Strategies wt prune uses to find merged branches. Each one also detects
+// what the previous ones do, at the cost of more git commands per branch.
+const (
+	// PruneStrategyAncestry finds branches whose tip is in the default branch
+	PruneStrategyAncestry = "ancestry"
+	// PruneStrategyRebase also finds rebased and cherry-picked branches
+	PruneStrategyRebase = "rebase"
+	// PruneStrategySquash also finds branches squash-merged into one commit
+	PruneStrategySquash = "squash"
+)
+
+// PruneStrategies lists the prune strategies from cheapest to most thorough
+var PruneStrategies = []string{PruneStrategyAncestry, PruneStrategyRebase, PruneStrategySquash}
+
+// Hook event names as used in the "hooks" config section
+const (
+	HookPreCreate  = "pre-create"
+	HookPostCreate = "post-create"
+	HookPreRemove  = "pre-remove"
+	HookPostRemove = "post-remove"
+	HookPostPrune  = "post-prune"
+)
+
+// HookEvents lists all hook events in lifecycle order
+var HookEvents = []string{HookPreCreate, HookPostCreate, HookPreRemove, HookPostRemove, HookPostPrune}
+
+// Hooks holds the commands to run at each lifecycle stage.
+// Failing pre-* hooks abort the operation; failing post-* hooks are reported only.
+type Hooks struct {
+	PreCreate  []Command `json:"pre-create,omitempty"`
+	PostCreate []Command `json:"post-create,omitempty"`
+	PreRemove  []Command `json:"pre-remove,omitempty"`
+	PostRemove []Command `json:"post-remove,omitempty"`
+	PostPrune  []Command `json:"post-prune,omitempty"`
+}
+
+// Commands returns the commands configured for the given hook event
+func (h Hooks) Commands(event string) []Command {
+	switch event {
+	case HookPreCreate:
+		return h.PreCreate
+	case HookPostCreate:
+		return h.PostCreate
+	case HookPreRemove:
+		return h.PreRemove
+	case HookPostRemove:
+		return h.PostRemove
+	case HookPostPrune:
+		return h.PostPrune
+	}
+	return nil
+}
+
+// HasCommands returns true if the config defines any postCreateCmd or hook commands
+func (c *Config) HasCommands() bool {
+	if len(c.PostCreateCmd) > 0 {
+		return true
+	}
+	for _, event := range HookEvents {
+		if len(c.Hooks.Commands(event)) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func GetConfigPath(repoRoot string) string {
@@ -33,6 +110,14 @@ func LoadConfig(repoRoot string) (*Config, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	if _, err := cfg.LockTimeoutDuration(); err != nil {
+		return nil, err
+	}
+	if cfg.PruneStrategy != "" {
+		if err := ValidatePruneStrategy(cfg.PruneStrategy); err != nil {
+			return nil, fmt.Errorf("invalid pruneStrategy: %w", err)
+		}
+	}
 
 	return &cfg, nil
 }
@@ -58,6 +143,96 @@ func (c *Config) Write(repoRoot string) error {
 	return nil
 }
 
+// Edit changes keys of the config file in place through edit, which gets
+// the file's JSON object (empty if there is no file). Unlike Write, keys
+// unknown to Config are kept; keys are written in sorted order.
+func Edit(repoRoot string, edit func(raw map[string]interface{})) error {
+	configPath := GetConfigPath(repoRoot)
+	raw := make(map[string]interface{})
+	data, err := os.ReadFile(configPath)
+	if err == nil {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	edit(raw)
+	data, err = json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Atomic write: temp file + rename
+	tempFile := configPath + ".tmp"
+	if err := os.WriteFile(tempFile, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFile, configPath); err != nil {
+		_ = os.Remove(tempFile)
+		return err
+	}
+	return nil
+}
+
+// RemoveKeys deletes keys as reported by CheckUnknownKeys (e.g. "foo" or
+// "hooks.bar") from the config file
+func RemoveKeys(repoRoot string, keys []string) error {
+	return Edit(repoRoot, func(raw map[string]interface{}) {
+		for _, k := range keys {
+			if event, ok := strings.CutPrefix(k, "hooks."); ok {
+				if hooks, ok := raw["hooks"].(map[string]interface{}); ok {
+					delete(hooks, event)
+				}
+				continue
+			}
+			delete(raw, k)
+		}
+	})
+}
+
+// LockTimeoutDuration returns the parsed lockTimeout, or 0 if none is set
+func (c *Config) LockTimeoutDuration() (time.Duration, error) {
+	if c.LockTimeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.LockTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lockTimeout %q: %w", c.LockTimeout, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid lockTimeout %q: must be positive", c.LockTimeout)
+	}
+	return d, nil
+}
+
+// GetRemote returns the remote holding the canonical branches: the remote
+// key, or origin if it is not set
+func (c *Config) GetRemote() string {
+	if c.Remote == "" {
+		return DefaultRemote
+	}
+	return c.Remote
+}
+
+// GetPruneStrategy returns the pruneStrategy key, or ancestry if it is not set
+func (c *Config) GetPruneStrategy() string {
+	if c.PruneStrategy == "" {
+		return PruneStrategyAncestry
+	}
+	return c.PruneStrategy
+}
+
+// ValidatePruneStrategy returns an error if strategy is not one of
+// PruneStrategies
+func ValidatePruneStrategy(strategy string) error {
+	if !slices.Contains(PruneStrategies, strategy) {
+		return fmt.Errorf("unknown prune strategy %q (want %s)", strategy, strings.Join(PruneStrategies, ", "))
+	}
+	return nil
+}
+
 func (c *Config) GetWorktreeBase(repoRoot string) string {
 	if c.WorktreePathTemplate == "" {
 		return repoRoot + ".wt"
@@ -87,6 +262,16 @@ func CheckUnknownKeys(repoRoot string) ([]string, error) {
 		"worktreeCopyPatterns":     true,
 		"postCreateCmd":            true,
 		"deleteBranchWithWorktree": true,
+		"hooks":                    true,
+		"lockTimeout":              true,
+		"remote":                   true,
+		"pruneStrategy":            true,
+		"pruneGone":                true,
+	}
+
+	knownHooks := make(map[string]bool)
+	for _, event := range HookEvents {
+		knownHooks[event] = true
 	}
 
 	var unknown []string
@@ -95,5 +280,12 @@ func CheckUnknownKeys(repoRoot string) ([]string, error) {
 			unknown = append(unknown, k)
 		}
 	}
+	if hooks, ok := raw["hooks"].(map[string]interface{}); ok {
+		for k := range hooks {
+			if !knownHooks[k] {
+				unknown = append(unknown, "hooks."+k)
+			}
+		}
+	}
 	return unknown, nil
 }
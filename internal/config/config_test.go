@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestGetWorktreeBase(t *testing.T) {
@@ -126,7 +127,7 @@ func TestConfigWrite(t *testing.T) {
 		DefaultBranch:            "main",
 		WorktreePathTemplate:     "$REPO_PATH.wt",
 		WorktreeCopyPatterns:     []string{".env"},
-		PostCreateCmd:            []string{"npm install"},
+		PostCreateCmd:            Commands("npm install"),
 		DeleteBranchWithWorktree: false,
 	}
 
@@ -187,6 +188,54 @@ func TestCheckUnknownKeys(t *testing.T) {
 	}
 }
 
+func TestRemoveKeys(t *testing.T) {
+	tempDir := t.TempDir()
+	configContent := `{
+		"defaultBranch": "main",
+		"unknownKey": "value",
+		"hooks": {"post-create": ["make"], "pre-push": ["x"]}
+	}`
+	if err := os.WriteFile(GetConfigPath(tempDir), []byte(configContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	unknown, err := CheckUnknownKeys(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveKeys(tempDir, unknown); err != nil {
+		t.Fatalf("RemoveKeys failed: %v", err)
+	}
+
+	if unknown, _ := CheckUnknownKeys(tempDir); len(unknown) != 0 {
+		t.Errorf("expected no unknown keys after RemoveKeys, got: %v", unknown)
+	}
+	cfg, err := LoadConfig(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultBranch != "main" || len(cfg.Hooks.PostCreate) != 1 {
+		t.Errorf("expected known keys to be kept, got: %+v", cfg)
+	}
+}
+
+func TestEdit_MissingFile(t *testing.T) {
+	tempDir := t.TempDir()
+	err := Edit(tempDir, func(raw map[string]interface{}) {
+		raw["defaultBranch"] = "trunk"
+	})
+	if err != nil {
+		t.Fatalf("Edit failed: %v", err)
+	}
+	cfg, err := LoadConfig(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultBranch != "trunk" {
+		t.Errorf("expected defaultBranch trunk, got %q", cfg.DefaultBranch)
+	}
+}
+
 func TestCheckUnknownKeys_MissingFile(t *testing.T) {
 	unknown, err := CheckUnknownKeys("/nonexistent/path")
 	if err != nil {
@@ -204,3 +253,117 @@ func TestGetConfigPath(t *testing.T) {
 		t.Errorf("GetConfigPath = %q, want %q", path, expected)
 	}
 }
+
+func TestHooksCommands(t *testing.T) {
+	hooks := Hooks{
+		PreCreate:  Commands("a"),
+		PostCreate: Commands("b"),
+		PreRemove:  Commands("c"),
+		PostRemove: Commands("d"),
+		PostPrune:  Commands("e"),
+	}
+
+	tests := map[string]string{
+		HookPreCreate:  "a",
+		HookPostCreate: "b",
+		HookPreRemove:  "c",
+		HookPostRemove: "d",
+		HookPostPrune:  "e",
+	}
+	for event, want := range tests {
+		got := hooks.Commands(event)
+		if len(got) != 1 || got[0].Line != want {
+			t.Errorf("Commands(%q) = %v, want [%s]", event, got, want)
+		}
+	}
+
+	if got := hooks.Commands("pre-prune"); got != nil {
+		t.Errorf("Commands(%q) = %v, want nil", "pre-prune", got)
+	}
+}
+
+func TestCheckUnknownKeys_Hooks(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configPath := filepath.Join(tempDir, ".wt.config.json")
+	configContent := `{
+		"hooks": {
+			"pre-create": ["true"],
+			"post-remove": ["true"],
+			"pre-prune": ["true"]
+		}
+	}`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	unknown, err := CheckUnknownKeys(tempDir)
+	if err != nil {
+		t.Fatalf("CheckUnknownKeys failed: %v", err)
+	}
+	if len(unknown) != 1 || unknown[0] != "hooks.pre-prune" {
+		t.Errorf("expected [hooks.pre-prune], got %v", unknown)
+	}
+}
+
+func TestLockTimeoutDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{value: "", expected: 0},
+		{value: "30s", expected: 30 * time.Second},
+		{value: "2m", expected: 2 * time.Minute},
+		{value: "soon", wantErr: true},
+		{value: "0s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{LockTimeout: tt.value}
+		got, err := cfg.LockTimeoutDuration()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LockTimeoutDuration(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("LockTimeoutDuration(%q) = %v, want %v", tt.value, got, tt.expected)
+		}
+	}
+
+	// LoadConfig rejects an invalid value
+	tempDir := t.TempDir()
+	if err := os.WriteFile(GetConfigPath(tempDir), []byte(`{"lockTimeout": "soon"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(tempDir); err == nil {
+		t.Error("expected LoadConfig to reject an invalid lockTimeout")
+	}
+}
+
+func TestGetRemote(t *testing.T) {
+	if got := (&Config{}).GetRemote(); got != DefaultRemote {
+		t.Errorf("GetRemote() = %q, want %q", got, DefaultRemote)
+	}
+	if got := (&Config{Remote: "upstream"}).GetRemote(); got != "upstream" {
+		t.Errorf("GetRemote() = %q, want upstream", got)
+	}
+}
+
+func TestGetPruneStrategy(t *testing.T) {
+	if got := (&Config{}).GetPruneStrategy(); got != PruneStrategyAncestry {
+		t.Errorf("GetPruneStrategy() = %q, want %q", got, PruneStrategyAncestry)
+	}
+	if got := (&Config{PruneStrategy: PruneStrategySquash}).GetPruneStrategy(); got != PruneStrategySquash {
+		t.Errorf("GetPruneStrategy() = %q, want %q", got, PruneStrategySquash)
+	}
+
+	// LoadConfig rejects an unknown strategy
+	tempDir := t.TempDir()
+	if err := os.WriteFile(GetConfigPath(tempDir), []byte(`{"pruneStrategy": "patch-id"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(tempDir); err == nil {
+		t.Error("expected LoadConfig to reject an unknown pruneStrategy")
+	}
+}
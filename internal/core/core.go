@@ -1,12 +1,15 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,11 +28,50 @@ var branchNameRegex = regexp.MustCompile(branchNamePattern)
 // DefaultLockTimeout is the timeout for acquiring the repository lock
 const DefaultLockTimeout = 5 * time.Second
 
+// lockTimeoutOverride is set by --lock-timeout and takes precedence over config
+var lockTimeoutOverride time.Duration
+
+// SetLockTimeout overrides the lock timeout for this process (--lock-timeout)
+func SetLockTimeout(d time.Duration) {
+	lockTimeoutOverride = d
+}
+
+// lockTimeout returns how long to wait for the repository lock:
+// --lock-timeout, then the lockTimeout config key, then DefaultLockTimeout.
+func lockTimeout(cfg *config.Config) time.Duration {
+	if lockTimeoutOverride > 0 {
+		return lockTimeoutOverride
+	}
+	if d, err := cfg.LockTimeoutDuration(); err == nil && d > 0 {
+		return d
+	}
+	return DefaultLockTimeout
+}
+
+// remoteOverride is set by --remote and takes precedence over config
+var remoteOverride string
+
+// SetRemote overrides the remote for this process (--remote)
+func SetRemote(name string) {
+	remoteOverride = name
+}
+
+// remoteName returns the remote wt detects the default branch on and
+// creates branches from: --remote, else the remote config key, else origin
+func remoteName(cfg *config.Config) string {
+	if remoteOverride != "" {
+		return remoteOverride
+	}
+	return cfg.GetRemote()
+}
+
 // RepoEnv holds the common environment needed for worktree operations
 type RepoEnv struct {
 	Root          string
 	Config        *config.Config
 	DefaultBranch string
+	// Remote is the remote holding the canonical branches (see remoteName)
+	Remote string
 }
 
 // LoadRepoEnv loads the repository environment (root, config, default branch)
@@ -44,15 +86,14 @@ func LoadRepoEnv() (*RepoEnv, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	defaultBranch := cfg.DefaultBranch
-	if defaultBranch == "" {
-		defaultBranch, _ = git.GetDefaultBranch()
-	}
+	// Commands that need the default branch fail when they find it empty
+	defaultBranch, _, _ := DetectDefaultBranch(root, cfg)
 
 	return &RepoEnv{
 		Root:          root,
 		Config:        cfg,
 		DefaultBranch: defaultBranch,
+		Remote:        remoteName(cfg),
 	}, nil
 }
 
@@ -71,7 +112,8 @@ func MapBranchToDir(branch string) (string, error) {
 
 // FindWorktree returns the path to an existing worktree for the given branch.
 // It handles the default branch special case (returns repo root).
-// It returns an error if no worktree exists for the branch.
+// It returns an error if no worktree exists for the branch. Detached and bare
+// worktrees have no branch; ResolveWorktree addresses them by path or commit.
 func FindWorktree(branch string) (string, error) {
 	env, err := LoadRepoEnv()
 	if err != nil {
@@ -93,7 +135,7 @@ func FindWorktree(branch string) (string, error) {
 	}
 
 	for _, wt := range worktrees {
-		if wt.Branch == branch {
+		if !wt.Detached && !wt.Bare && wt.Branch == branch {
 			return filepath.Clean(wt.Path), nil
 		}
 	}
@@ -101,6 +143,78 @@ func FindWorktree(branch string) (string, error) {
 	return "", fmt.Errorf("no worktree exists for branch %q", branch)
 }
 
+// isExplicitPath reports whether target can only be meant as a path: it is
+// absolute or starts with ./ or ../, which no branch name does
+func isExplicitPath(target string) bool {
+	target = filepath.ToSlash(target)
+	return filepath.IsAbs(target) || target == "." || target == ".." ||
+		strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../")
+}
+
+// awaitCreationLock acquires the directory lock for creating a worktree.
+// Setup commands may run for minutes, so it waits as long as the holder is
+// alive; the lock timeout only applies to holders that are gone.
+func awaitCreationLock(env *RepoEnv, dirName string) (func() error, error) {
+	return git.AwaitDirLock(env.Root, dirName, lockTimeout(env.Config), func(holder *git.LockInfo) {
+		if holder != nil {
+			log.Warnf("waiting for %s, which is working on %s", holder, dirName)
+		} else {
+			log.Warnf("waiting for another wt operation on %s", dirName)
+		}
+	})
+}
+
+// awaitWorktree returns path, the existing worktree of branch, once no other
+// process is creating it: git registers a worktree before its setup commands
+// run, and a failed setup removes it again. It returns "" if the worktree is
+// gone by then.
+func awaitWorktree(env *RepoEnv, branch, path string) (string, error) {
+	if path == filepath.Clean(env.Root) {
+		return path, nil
+	}
+	unlockDir, err := awaitCreationLock(env, filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	_ = unlockDir()
+	if path, err := FindWorktree(branch); err == nil {
+		return path, nil
+	}
+	return "", nil
+}
+
+// resolveExisting returns the path of an existing worktree that target
+// addresses although it is no branch name: any worktree by explicit path, or
+// a detached worktree by directory name, path or commit (see
+// ResolveWorktree). Otherwise it returns "", and target is created as a new
+// branch with the usual collision checks. Ambiguous targets, explicit paths
+// without a worktree and worktrees whose directory is gone are errors.
+func resolveExisting(target string) (string, error) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return "", err
+	}
+	explicitPath := isExplicitPath(target)
+	var candidates []git.Worktree
+	for _, wt := range worktrees {
+		if explicitPath || wt.Detached {
+			candidates = append(candidates, wt)
+		}
+	}
+	i, err := resolveWorktree(candidates, target)
+	if err != nil {
+		if errors.Is(err, errNoWorktree) && !explicitPath {
+			return "", nil
+		}
+		return "", err
+	}
+	wt := candidates[i]
+	if wt.Prunable {
+		return "", fmt.Errorf("worktree %s is prunable (its directory is gone); use wt remove", wt.Path)
+	}
+	return filepath.Clean(wt.Path), nil
+}
+
 // RollbackError represents a failure that triggered a rollback attempt.
 type RollbackError struct {
 	OriginalErr    error
@@ -114,17 +228,50 @@ func (e *RollbackError) Error() string {
 
 // EnsureWorktree ensures a worktree exists for the given branch and returns its path
 func EnsureWorktree(branch, base string) (string, error) {
-	// 1. Try to find existing worktree first
-	if path, err := FindWorktree(branch); err == nil {
-		return path, nil
+	path, err := ensureWorktree(branch, base)
+	if err == nil {
+		recordAccess(path)
 	}
+	return path, err
+}
 
-	// 2. Not found, proceed with creation
+func ensureWorktree(branch, base string) (string, error) {
 	env, err := LoadRepoEnv()
 	if err != nil {
 		return "", err
 	}
 
+	// 1. Try to find existing worktree first
+	if path, err := FindWorktree(branch); err == nil {
+		if path, err := awaitWorktree(env, branch, path); path != "" || err != nil {
+			return path, err
+		}
+	}
+
+	// 2. Not found, proceed with creation
+
+	// <remote>/<branch> creates a local branch tracking the remote one
+	branch, trackRemote := splitRemoteBranch(env, branch)
+	if trackRemote != "" {
+		if base != "" {
+			return "", fmt.Errorf("--from cannot be used with a remote branch (%s/%s)", trackRemote, branch)
+		}
+		if path, err := FindWorktree(branch); err == nil {
+			if path, err := awaitWorktree(env, branch, path); path != "" || err != nil {
+				return path, err
+			}
+		}
+	} else {
+		trackRemote = env.Remote
+		// A name that is no branch may address an existing worktree by path,
+		// or a detached one by directory name or commit (see resolveExisting)
+		if local, remote := git.BranchExists(branch, trackRemote); !local && !remote {
+			if path, err := resolveExisting(branch); path != "" || err != nil {
+				return path, err
+			}
+		}
+	}
+
 	// Case: Create new worktree
 	dirName, err := MapBranchToDir(branch)
 	if err != nil {
@@ -134,6 +281,30 @@ func EnsureWorktree(branch, base string) (string, error) {
 	wtRoot := env.Config.GetWorktreeBase(env.Root)
 	targetPath := filepath.Clean(filepath.Join(wtRoot, dirName))
 
+	// Repository-defined commands must be approved before anything is created
+	hasCommands := len(env.Config.PostCreateCmd) > 0 || hasHooks(env.Config, config.HookPreCreate, config.HookPostCreate)
+	if hasCommands {
+		if err := checkTrust(env); err != nil {
+			return "", err
+		}
+	}
+
+	// Concurrency Safety: the directory lock covers the whole creation, so
+	// other branches are created in parallel while a duplicate request for
+	// this branch waits for the first one to finish.
+	unlockDir, err := awaitCreationLock(env, dirName)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = unlockDir()
+	}()
+
+	// Another process may have created it while we waited
+	if path, err := FindWorktree(branch); err == nil {
+		return path, nil
+	}
+
 	worktrees, err := git.ListWorktrees()
 	if err != nil {
 		return "", err
@@ -153,18 +324,14 @@ func EnsureWorktree(branch, base string) (string, error) {
 		return "", fmt.Errorf("failed to create worktree root: %w", err)
 	}
 
-	// Concurrency Safety: Acquire lock before modification
-	unlock, err := git.AcquireLock(env.Root, DefaultLockTimeout)
-	if err != nil {
-		return "", err
-	}
-	defer func() {
-		_ = unlock()
-	}()
-
 	// Resolve branch source if it doesn't exist locally
-	local, remote := git.BranchExists(branch)
+	local, remote := git.BranchExists(branch, trackRemote)
 	isNewBranch := !local && !remote
+	// Branches only on the remote get a local branch tracking them
+	trackFrom := ""
+	if !local && remote {
+		trackFrom = trackRemote
+	}
 
 	if isNewBranch {
 		if base == "" {
@@ -172,34 +339,75 @@ func EnsureWorktree(branch, base string) (string, error) {
 				return "", fmt.Errorf("branch %s not found and no default branch detected. Use --from", branch)
 			}
 			base = env.DefaultBranch
+			// In clones of a fork the default branch may only exist on the remote
+			if local, onRemote := git.BranchExists(base, env.Remote); !local && onRemote {
+				base = env.Remote + "/" + base
+			}
 		}
 	} else {
-		// Branch exists (locally or on origin).
+		// Branch exists (locally or on the remote).
 		// We ignore 'base' because we are checking out an existing reference.
 		base = ""
 	}
 
-	if err := git.CreateWorktree(targetPath, branch, base); err != nil {
+	cmdEnv := CommandEnv{
+		Branch:        branch,
+		Path:          targetPath,
+		RepoRoot:      env.Root,
+		Base:          base,
+		DefaultBranch: env.DefaultBranch,
+		IsNewBranch:   isNewBranch,
+		DirName:       dirName,
+	}
+
+	// Command output goes to stderr and is kept in the post-create log,
+	// which outlives a rollback so failures can be inspected with wt logs
+	var logFile io.WriteCloser
+	if hasCommands {
+		logFile = createLog(env.Root, dirName, branch)
+		if logFile != nil {
+			defer func() {
+				_ = logFile.Close()
+			}()
+		}
+	}
+
+	// pre-create hook: a failure aborts before anything is created
+	if err := runHook(env.Config, config.HookPreCreate, env.Root, cmdEnv, logFile); err != nil {
+		return "", err
+	}
+
+	if err := withRepoLock(env, func() error {
+		if trackFrom != "" {
+			return git.CreateTrackingWorktree(targetPath, branch, trackFrom)
+		}
+		return git.CreateWorktree(targetPath, branch, base)
+	}); err != nil {
 		return "", err
 	}
 
 	// Success from here: attempt post-creation steps
-	if err := applyPostCreation(env.Root, targetPath, env.Config, isNewBranch, branch); err != nil {
+	if err := applyPostCreation(env.Config, cmdEnv, logFile); err != nil {
 		// Rollback on failure
 		var status string
-		rbErr := git.RemoveWorktree(targetPath, true)
-		if rbErr != nil {
-			status = fmt.Sprintf("failed to remove worktree: %v", rbErr)
-		} else {
+		rbErr := withRepoLock(env, func() error {
+			if err := git.RemoveWorktree(targetPath, true, false); err != nil {
+				status = fmt.Sprintf("failed to remove worktree: %v", err)
+				return err
+			}
 			status = "worktree removed"
-			if isNewBranch {
-				rbErr = git.DeleteBranch(branch)
-				if rbErr != nil {
-					status += fmt.Sprintf(", failed to delete branch: %v", rbErr)
-				} else {
-					status += ", branch deleted"
+			if isNewBranch || trackFrom != "" {
+				if err := git.DeleteBranch(branch); err != nil {
+					status += fmt.Sprintf(", failed to delete branch: %v", err)
+					return err
 				}
+				status += ", branch deleted"
 			}
+			return nil
+		})
+		if status == "" {
+			// The repository lock could not be acquired
+			status = fmt.Sprintf("failed to remove worktree: %v", rbErr)
 		}
 
 		if status == "worktree removed" || status == "worktree removed, branch deleted" {
@@ -213,19 +421,50 @@ func EnsureWorktree(branch, base string) (string, error) {
 		}
 	}
 
+	reportHook(env.Config, config.HookPostCreate, targetPath, cmdEnv, logFile)
+
 	return targetPath, nil
 }
 
+// splitRemoteBranch splits a "<remote>/<branch>" argument into the branch
+// and the remote, if it names a branch on a configured remote. As in git,
+// local branches take precedence: if name is a local branch, or matches no
+// remote branch, it is returned unchanged with an empty remote.
+func splitRemoteBranch(env *RepoEnv, name string) (string, string) {
+	if local, _ := git.BranchExists(name, env.Remote); local {
+		return name, ""
+	}
+	remotes, err := git.ListRemotes()
+	if err != nil {
+		return name, ""
+	}
+	for _, remote := range remotes {
+		branch, ok := strings.CutPrefix(name, remote+"/")
+		if !ok || branch == "" {
+			continue
+		}
+		if _, onRemote := git.BranchExists(branch, remote); onRemote {
+			return branch, remote
+		}
+	}
+	return name, ""
+}
+
 // checkCollisions verifies that the branch name does not collide with existing
 // worktrees or other branches that would map to the same directory name.
-// It checks both existing worktrees and all local branches.
-func checkCollisions(branch, dirName string, existing []git.Worktree) error {
+// It checks both existing worktrees and all local branches. Branches in
+// ignore (e.g. the old name of a branch being renamed) are not collisions.
+func checkCollisions(branch, dirName string, existing []git.Worktree, ignore ...string) error {
+	ignored := func(b string) bool {
+		return b == branch || slices.Contains(ignore, b)
+	}
+
 	// Check existing worktrees
 	for _, wt := range existing {
 		// We already checked for exact branch match.
 		// Now check if a different branch maps to the same dir name.
 		existingDir := filepath.Base(wt.Path)
-		if existingDir == dirName && wt.Branch != branch {
+		if existingDir == dirName && !ignored(wt.Branch) {
 			return fmt.Errorf("collision: branch %q maps to same directory %q as existing worktree for branch %q",
 				branch, dirName, wt.Branch)
 		}
@@ -238,7 +477,7 @@ func checkCollisions(branch, dirName string, existing []git.Worktree) error {
 		return nil // skip if we can't list branches, not fatal here
 	}
 	for _, b := range branches {
-		if b == branch {
+		if ignored(b) {
 			continue
 		}
 		d, err := MapBranchToDir(b)
@@ -254,8 +493,20 @@ func checkCollisions(branch, dirName string, existing []git.Worktree) error {
 	return nil
 }
 
-// RemoveWorktree handles the safety logic for removing a worktree
-func RemoveWorktree(branch string, force bool, confirmFn func(string) bool) error {
+// RemoveOptions controls RemoveWorktree
+type RemoveOptions struct {
+	// Force removes the worktree even if it is dirty
+	Force bool
+	// IgnoreLock removes the worktree even if it is locked (see LockWorktree)
+	IgnoreLock bool
+	// Confirm is asked whether to remove a dirty worktree; nil declines
+	Confirm func(string) bool
+}
+
+// RemoveWorktree handles the safety logic for removing a worktree. target
+// is resolved with ResolveWorktree, so detached and prunable worktrees can be
+// given by path or commit.
+func RemoveWorktree(target string, opts RemoveOptions) error {
 	env, err := LoadRepoEnv()
 	if err != nil {
 		return err
@@ -265,161 +516,353 @@ func RemoveWorktree(branch string, force bool, confirmFn func(string) bool) erro
 		return fmt.Errorf("could not determine default branch")
 	}
 
-	if branch == env.DefaultBranch {
-		return fmt.Errorf("refusing to remove default branch/main worktree")
-	}
-
-	worktrees, err := git.ListWorktrees()
+	targetWt, isMain, err := ResolveWorktree(target)
 	if err != nil {
 		return err
 	}
-
-	var targetWt *git.Worktree
-	for _, wt := range worktrees {
-		if wt.Branch == branch {
-			targetWt = &wt
-			break
-		}
+	if isMain || targetWt.Branch == env.DefaultBranch {
+		return fmt.Errorf("refusing to remove default branch/main worktree")
 	}
+	label := worktreeLabel(targetWt)
 
-	if targetWt == nil {
-		return fmt.Errorf("no worktree found for branch %s", branch)
+	if targetWt.Locked && !opts.IgnoreLock {
+		return fmt.Errorf("worktree %s is %s; use --ignore-lock or wt unlock", label, DescribeLock(targetWt))
 	}
 
-	// Dirty check
-	dirty, err := git.IsDirty(targetWt.Path)
-	if err != nil {
-		return err
+	// Dirty check; a prunable worktree has no directory left to check
+	force := opts.Force
+	if !targetWt.Prunable {
+		dirty, err := git.IsDirty(targetWt.Path)
+		if err != nil {
+			return err
+		}
+
+		if dirty && !force {
+			if opts.Confirm == nil || !opts.Confirm(fmt.Sprintf("Worktree %s is dirty. Remove anyway?", label)) {
+				return fmt.Errorf("worktree is dirty; use --force or confirm")
+			}
+			force = true // If confirmed, we can use --force for the git command
+		}
 	}
 
-	if dirty && !force {
-		if confirmFn == nil || !confirmFn(fmt.Sprintf("Worktree %s is dirty. Remove anyway?", branch)) {
-			return fmt.Errorf("worktree is dirty; use --force or confirm")
+	// Repository-defined commands must be approved before anything is removed
+	if hasHooks(env.Config, config.HookPreRemove, config.HookPostRemove) {
+		if err := checkTrust(env); err != nil {
+			return err
 		}
-		force = true // If confirmed, we can use --force for the git command
 	}
 
-	// Concurrency Safety: Acquire lock before modification
-	unlock, err := git.AcquireLock(env.Root, DefaultLockTimeout)
+	dirName := filepath.Base(targetWt.Path)
+
+	// Concurrency Safety: Lock the worktree directory for the whole removal
+	unlockDir, err := git.AcquireDirLock(env.Root, dirName, lockTimeout(env.Config), nil)
 	if err != nil {
 		return err
 	}
 	defer func() {
-		_ = unlock()
+		_ = unlockDir()
 	}()
-	if err := git.RemoveWorktree(targetWt.Path, force); err != nil {
+
+	if _, err := os.Stat(targetWt.Path); os.IsNotExist(err) && !targetWt.Prunable {
+		return fmt.Errorf("worktree for %s was removed by another process", label)
+	}
+
+	cmdEnv := CommandEnv{
+		Path:          targetWt.Path,
+		RepoRoot:      env.Root,
+		DefaultBranch: env.DefaultBranch,
+		DirName:       dirName,
+	}
+	if !targetWt.Detached {
+		cmdEnv.Branch = targetWt.Branch
+	}
+
+	// pre-remove hook: a failure aborts before anything is removed
+	if err := runHook(env.Config, config.HookPreRemove, hookDir(env, targetWt), cmdEnv, nil); err != nil {
 		return err
 	}
 
-	if env.Config.DeleteBranchWithWorktree {
-		mainBranch, _ := git.GetCurrentBranchInMainWorktree(env.Root)
-		if branch != mainBranch && branch != env.DefaultBranch {
-			if err := git.DeleteBranch(branch); err != nil {
-				log.Warnf("failed to delete branch %s: %v", branch, err)
+	if err := withRepoLock(env, func() error {
+		if err := git.RemoveWorktree(targetWt.Path, force, targetWt.Locked); err != nil {
+			return err
+		}
+		if env.Config.DeleteBranchWithWorktree && !targetWt.Detached {
+			branch := targetWt.Branch
+			mainBranch, _ := git.GetCurrentBranchInMainWorktree(env.Root)
+			if branch != mainBranch && branch != env.DefaultBranch {
+				if err := git.DeleteBranch(branch); err != nil {
+					log.Warnf("failed to delete branch %s: %v", branch, err)
+				}
 			}
 		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
+	reportHook(env.Config, config.HookPostRemove, env.Root, cmdEnv, nil)
+
 	return nil
 }
 
+// hookDir returns the directory pre-remove hooks run in: the worktree, or
+// the repository root if the worktree directory is already gone
+func hookDir(env *RepoEnv, wt git.Worktree) string {
+	if wt.Prunable {
+		return env.Root
+	}
+	return wt.Path
+}
+
 type PruneOptions struct {
 	DryRun bool
 	Force  bool
 	Fetch  bool
+	// IgnoreLock also prunes locked worktrees
+	IgnoreLock bool
+	// Strategy selects how merged branches are found (see
+	// config.PruneStrategies); empty uses the pruneStrategy config key
+	Strategy string
+	// Gone also prunes branches whose upstream is gone (see
+	// git.ListGoneBranches); nil uses the pruneGone config key
+	Gone *bool
+	// OlderThan also prunes branches whose last commit is older; 0 disables
+	OlderThan time.Duration
+	// InactiveFor also prunes worktrees without commits, file changes or
+	// access through wt for that long (see ageRule); 0 disables
+	InactiveFor time.Duration
+	// DeleteUnmerged lets deleteBranchWithWorktree delete the branches of
+	// worktrees no merge rule selected, with their unmerged commits
+	DeleteUnmerged bool
 }
 
-// PruneWorktrees removes worktrees whose branches are merged into the default branch
-func PruneWorktrees(opts PruneOptions) (int, []string, error) {
+// PruneEntry is a worktree considered by PruneWorktrees
+type PruneEntry struct {
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+	// Rule is the rule that selected the worktree, e.g. PruneRuleSquashed
+	Rule string `json:"rule"`
+	// LastCommit, LastModified and LastAccess are what the age rules judged
+	// the worktree by; zero for the other rules and for unknown times
+	LastCommit   time.Time `json:"lastCommit,omitzero"`
+	LastModified time.Time `json:"lastModified,omitzero"`
+	LastAccess   time.Time `json:"lastAccess,omitzero"`
+	// Reason explains why a candidate was skipped; empty for pruned worktrees
+	Reason string `json:"reason,omitempty"`
+	// BranchKept is set if deleteBranchWithWorktree would delete the branch,
+	// but it is kept because no merge rule matched and DeleteUnmerged was
+	// not given
+	BranchKept bool `json:"branchKept,omitempty"`
+}
+
+// PruneResult lists the selected worktrees that were pruned (or, in a dry run,
+// would be pruned) and those that were skipped, with the reason.
+type PruneResult struct {
+	Pruned  []PruneEntry
+	Skipped []PruneEntry
+}
+
+// PruneWorktrees removes worktrees whose branches are merged into the default
+// branch by one of the rules opts.Strategy allows (see mergeDetector) and,
+// if opts.Gone is set, those whose upstream branch is gone. opts.OlderThan
+// and opts.InactiveFor also select unmerged worktrees by age (see ageRule).
+func PruneWorktrees(opts PruneOptions) (*PruneResult, error) {
 	env, err := LoadRepoEnv()
 	if err != nil {
-		return 0, nil, err
+		return nil, err
 	}
 
 	if opts.Fetch {
-		if err := git.FetchPrune(); err != nil {
+		if err := git.FetchPrune(env.Remote); err != nil {
 			log.Warnf("failed to fetch and prune: %v", err)
 		}
 	}
 
 	if env.DefaultBranch == "" {
-		return 0, nil, fmt.Errorf("could not determine default branch")
+		return nil, fmt.Errorf("could not determine default branch")
 	}
 
-	merged, err := git.GetMergedBranches(env.DefaultBranch)
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = env.Config.GetPruneStrategy()
+	}
+	detector, err := newMergeDetector(env.DefaultBranch, strategy)
 	if err != nil {
-		return 0, nil, err
+		return nil, err
 	}
 
-	mergedSet := make(map[string]bool)
-	for _, b := range merged {
-		mergedSet[b] = true
+	pruneGone := env.Config.PruneGone
+	if opts.Gone != nil {
+		pruneGone = *opts.Gone
+	}
+	goneSet := make(map[string]bool)
+	if pruneGone {
+		gone, err := git.ListGoneBranches()
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range gone {
+			goneSet[b] = true
+		}
 	}
 
 	worktrees, err := git.ListWorktrees()
 	if err != nil {
-		return 0, nil, err
+		return nil, err
 	}
 
 	mainBranch, _ := git.GetCurrentBranchInMainWorktree(env.Root)
 
-	var candidates []string
-	prunedCount := 0
+	result := &PruneResult{}
+	now := time.Now()
 
-	// Concurrency Safety: Acquire lock before modification (only if not dry run)
-	if !opts.DryRun {
-		unlock, err := git.AcquireLock(env.Root, DefaultLockTimeout)
-		if err != nil {
-			return 0, nil, err
+	// Repository-defined commands must be approved before anything is removed
+	if !opts.DryRun && hasHooks(env.Config, config.HookPreRemove, config.HookPostRemove, config.HookPostPrune) {
+		if err := checkTrust(env); err != nil {
+			return nil, err
 		}
-		defer func() {
-			_ = unlock()
-		}()
 	}
 
 	for i, wt := range worktrees {
 		if i == 0 {
 			continue // Skip main worktree
 		}
-		if wt.Branch == git.DetachedBranchName || wt.Branch == env.DefaultBranch {
+		if wt.Detached || wt.Bare || wt.Branch == env.DefaultBranch {
 			continue
 		}
 
-		if mergedSet[wt.Branch] {
-			dirty, err := git.IsDirty(wt.Path)
-			if err != nil {
-				log.Warnf("failed to check dirty status for %s: %v", wt.Branch, err)
+		entry := PruneEntry{Branch: wt.Branch, Path: wt.Path}
+		rule, err := detector.rule(wt.Branch)
+		if err != nil {
+			log.Warnf("failed to check whether %s is merged: %v", wt.Branch, err)
+			continue
+		}
+		if rule == "" && goneSet[wt.Branch] {
+			rule = PruneRuleGone
+		}
+		if rule == "" {
+			if rule, err = ageRule(env, wt, opts, now, &entry); err != nil {
+				log.Warnf("failed to check the age of %s: %v", wt.Branch, err)
+				continue
+			}
+		}
+		if rule != "" {
+			entry.Rule = rule
+			skip := func(reason string) {
+				entry.Reason = reason
+				result.Skipped = append(result.Skipped, entry)
+			}
+
+			if wt.Locked && !opts.IgnoreLock {
+				skip(DescribeLock(wt))
 				continue
 			}
 
+			dirty := false
+			if !wt.Prunable {
+				dirty, err = git.IsDirty(wt.Path)
+				if err != nil {
+					log.Warnf("failed to check dirty status for %s: %v", wt.Branch, err)
+					skip(fmt.Sprintf("failed to check dirty status: %v", err))
+					continue
+				}
+			}
+
 			if dirty && !opts.Force {
-				log.Infof("Skipping %s: worktree is dirty (use --force to prune)", wt.Branch)
+				skip("dirty")
 				continue
 			}
 
+			// Only merged branches are deleted: the others have commits that
+			// exist nowhere else once the branch is gone
+			deleteBranch := env.Config.DeleteBranchWithWorktree && wt.Branch != mainBranch
+			if deleteBranch && !isMergeRule(rule) && !opts.DeleteUnmerged {
+				deleteBranch = false
+				entry.BranchKept = true
+			}
+
 			if opts.DryRun {
-				candidates = append(candidates, wt.Branch)
+				result.Pruned = append(result.Pruned, entry)
+			} else if err := pruneWorktree(env, wt, opts.Force, deleteBranch); err != nil {
+				log.Errorf("skipping %s: %v", wt.Branch, err)
+				skip(err.Error())
 			} else {
-				if err := git.RemoveWorktree(wt.Path, opts.Force); err != nil {
-					log.Errorf("failed to remove worktree for %s: %v", wt.Branch, err)
-					continue
-				}
-				if env.Config.DeleteBranchWithWorktree && wt.Branch != mainBranch {
-					if err := git.DeleteBranch(wt.Branch); err != nil {
-						log.Warnf("failed to delete branch %s: %v", wt.Branch, err)
-					}
-				}
-				prunedCount++
+				result.Pruned = append(result.Pruned, entry)
+			}
+		}
+	}
+
+	if !opts.DryRun && len(result.Pruned) > 0 {
+		reportHook(env.Config, config.HookPostPrune, env.Root, CommandEnv{
+			RepoRoot:      env.Root,
+			DefaultBranch: env.DefaultBranch,
+		}, nil)
+	}
+
+	return result, nil
+}
+
+// pruneWorktree removes one worktree for PruneWorktrees, holding its
+// directory lock, and its branch if deleteBranch is set. The returned error
+// explains why the worktree was skipped.
+func pruneWorktree(env *RepoEnv, wt git.Worktree, force, deleteBranch bool) error {
+	dirName := filepath.Base(wt.Path)
+	unlockDir, err := git.AcquireDirLock(env.Root, dirName, lockTimeout(env.Config), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = unlockDir()
+	}()
+
+	cmdEnv := CommandEnv{
+		Branch:        wt.Branch,
+		Path:          wt.Path,
+		RepoRoot:      env.Root,
+		DefaultBranch: env.DefaultBranch,
+		DirName:       dirName,
+	}
+	if err := runHook(env.Config, config.HookPreRemove, hookDir(env, wt), cmdEnv, nil); err != nil {
+		return err
+	}
+
+	if err := withRepoLock(env, func() error {
+		if err := git.RemoveWorktree(wt.Path, force, wt.Locked); err != nil {
+			return fmt.Errorf("failed to remove worktree: %w", err)
+		}
+		if deleteBranch {
+			if err := git.DeleteBranch(wt.Branch); err != nil {
+				log.Warnf("failed to delete branch %s: %v", wt.Branch, err)
 			}
 		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	return prunedCount, candidates, nil
+	reportHook(env.Config, config.HookPostRemove, env.Root, cmdEnv, nil)
+	return nil
+}
+
+// withRepoLock runs fn while holding the repository lock. The lock only
+// covers git metadata changes, so keep fn short: no hooks or setup commands.
+func withRepoLock(env *RepoEnv, fn func() error) error {
+	unlock, err := git.AcquireLock(env.Root, lockTimeout(env.Config))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = unlock()
+	}()
+	return fn()
 }
 
 // applyPostCreation applies post-creation configuration to a new worktree.
 // It copies files matching the configured patterns and executes post-create commands.
-func applyPostCreation(repoRoot, targetPath string, cfg *config.Config, isNewBranch bool, branch string) error {
+// Command output is also written to logFile unless it is nil.
+func applyPostCreation(cfg *config.Config, cmdEnv CommandEnv, logFile io.Writer) error {
+	repoRoot, targetPath := cmdEnv.RepoRoot, cmdEnv.Path
+
 	// 1. Copy patterns
 	absRepoRoot, err := filepath.Abs(repoRoot)
 	if err != nil {
@@ -455,34 +898,110 @@ func applyPostCreation(repoRoot, targetPath string, cfg *config.Config, isNewBra
 	}
 
 	// 2. PostCreateCmd
-	for _, cmdStr := range cfg.PostCreateCmd {
-		if strings.TrimSpace(cmdStr) == "" {
-			log.Warnf("skipping empty postCreateCmd in config")
-			continue
-		}
-		parts := strings.Fields(cmdStr)
-		if len(parts) == 0 {
-			log.Warnf("skipping malformed postCreateCmd: %q", cmdStr)
+	return runCommands("postCreateCmd", cfg.PostCreateCmd, targetPath, cmdEnv.Environ(), logFile)
+}
+
+// runCommands executes configured commands sequentially in dir with the given
+// environment, stopping at the first failure. label names the config key in
+// warnings and errors. Output goes to stderr and, if set, logFile.
+func runCommands(label string, cmds []config.Command, dir string, environ []string, logFile io.Writer) error {
+	for _, c := range cmds {
+		if c.IsEmpty() {
+			log.Warnf("skipping empty %s in config", label)
 			continue
 		}
-
-		// Security: Validate command to prevent injection
-		if err := validatePostCreateCommand(parts); err != nil {
-			return fmt.Errorf("invalid postCreateCmd '%s': %w", cmdStr, err)
+		if logFile != nil {
+			_, _ = fmt.Fprintf(logFile, "$ %s\n", c)
 		}
-
-		cmd := exec.Command(parts[0], parts[1:]...)
-		cmd.Dir = targetPath
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("postCreateCmd '%s' failed: %w", cmdStr, err)
+		if err := runCommand(c, dir, environ, commandOutput(logFile)); err != nil {
+			err = fmt.Errorf("%s '%s' failed: %w", label, c, err)
+			if logFile != nil {
+				_, _ = fmt.Fprintf(logFile, "# %v\n", err)
+			}
+			return err
 		}
 	}
 
 	return nil
 }
 
+// commandWaitDelay is how long runCommand waits for the output of processes
+// that outlive a command
+const commandWaitDelay = time.Second
+
+// runCommand runs a single configured command with stdout and stderr sent to out.
+// Structured commands may set extra environment variables, a working directory
+// below dir and a timeout.
+func runCommand(c config.Command, dir string, environ []string, out io.Writer) error {
+	parts, err := c.Args()
+	if err != nil {
+		return fmt.Errorf("invalid command: %w", err)
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("invalid command: empty")
+	}
+
+	workDir, err := resolveCommandDir(dir, c.Dir)
+	if err != nil {
+		return err
+	}
+
+	timeout, err := c.TimeoutDuration()
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	cmd.Dir = workDir
+	cmd.Env = environ
+	for k, v := range c.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// out is a pipe unless it is a file, and processes the command left
+	// running (e.g. a daemon started in the background) keep it open:
+	// don't wait for them once the command exited or was killed
+	cmd.WaitDelay = commandWaitDelay
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	stopForwarding := forwardSignals(cmd.Process.Pid)
+	err = cmd.Wait()
+	stopForwarding()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	if errors.Is(err, exec.ErrWaitDelay) {
+		return nil // The command itself succeeded
+	}
+	return err
+}
+
+// resolveCommandDir resolves a command's relative working directory against
+// base. The result must stay inside base.
+func resolveCommandDir(base, rel string) (string, error) {
+	if rel == "" {
+		return base, nil
+	}
+	if filepath.IsAbs(rel) {
+		return "", fmt.Errorf("dir %q must be relative to the worktree", rel)
+	}
+	resolved := filepath.Join(base, rel)
+	if r, err := filepath.Rel(base, resolved); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("dir %q escapes the worktree", rel)
+	}
+	return resolved, nil
+}
+
 // copyIfMissing copies a file from src to dst only if dst does not already exist.
 // It creates the destination directory if needed.
 func copyIfMissing(src, dst string) error {
@@ -522,53 +1041,3 @@ func copyIfMissing(src, dst string) error {
 	_, err = io.Copy(destFile, sourceFile)
 	return err
 }
-
-// validatePostCreateCommand validates a postCreateCmd entry for security.
-// It prevents command injection by checking for dangerous patterns.
-func validatePostCreateCommand(parts []string) error {
-	if len(parts) == 0 {
-		return fmt.Errorf("empty command")
-	}
-
-	cmd := parts[0]
-	args := parts[1:]
-
-	// Check for shell interpreters that could enable injection
-	dangerousCommands := map[string]bool{
-		"sh":         true,
-		"bash":       true,
-		"zsh":        true,
-		"fish":       true,
-		"cmd":        true,
-		"powershell": true,
-		"pwsh":       true,
-		"python":     true,
-		"python3":    true,
-		"ruby":       true,
-		"perl":       true,
-		"node":       true,
-		"nodejs":     true,
-	}
-
-	// Get base command name (handle paths like /bin/sh or ./script.sh)
-	baseCmd := filepath.Base(cmd)
-	if dangerousCommands[baseCmd] {
-		return fmt.Errorf("shell interpreters and script engines are not allowed for security: %s", cmd)
-	}
-
-	// Check for dangerous argument patterns
-	for _, arg := range args {
-		// Prevent command chaining
-		if strings.Contains(arg, ";") || strings.Contains(arg, "&&") || strings.Contains(arg, "||") ||
-			strings.Contains(arg, "|") || strings.Contains(arg, "$") || strings.Contains(arg, "`") {
-			return fmt.Errorf("argument contains dangerous characters: %s", arg)
-		}
-
-		// Prevent path traversal attempts
-		if strings.Contains(arg, "..") {
-			return fmt.Errorf("argument contains path traversal: %s", arg)
-		}
-	}
-
-	return nil
-}
@@ -2,9 +2,12 @@ package core
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/trungung/wt/internal/config"
 	"github.com/trungung/wt/internal/log"
@@ -112,15 +115,15 @@ func TestApplyPostCreation_EmptyCommands(t *testing.T) {
 	defer log.SetOutput(os.Stderr)
 
 	cfg := &config.Config{
-		PostCreateCmd: []string{
+		PostCreateCmd: config.Commands(
 			"",           // empty string
 			"   ",        // whitespace only
 			"echo hello", // valid command
-		},
+		),
 	}
 
 	// Should not panic on empty commands
-	err = applyPostCreation(repoRoot, targetPath, cfg, false, "test-branch")
+	err = applyPostCreation(cfg, CommandEnv{RepoRoot: repoRoot, Path: targetPath, Branch: "test-branch"}, nil)
 	if err != nil {
 		t.Errorf("applyPostCreation should not error on empty commands, got: %v", err)
 	}
@@ -156,14 +159,14 @@ func TestApplyPostCreation_WhitespaceOnly(t *testing.T) {
 	defer log.SetOutput(os.Stderr)
 
 	cfg := &config.Config{
-		PostCreateCmd: []string{
+		PostCreateCmd: config.Commands(
 			"   ",  // only spaces
 			"\t\n", // tabs and newlines
-		},
+		),
 	}
 
 	// Should not panic and should skip whitespace-only commands
-	err = applyPostCreation(repoRoot, targetPath, cfg, false, "test-branch")
+	err = applyPostCreation(cfg, CommandEnv{RepoRoot: repoRoot, Path: targetPath, Branch: "test-branch"}, nil)
 	if err != nil {
 		t.Errorf("applyPostCreation should not error on whitespace-only commands, got: %v", err)
 	}
@@ -196,3 +199,162 @@ func TestMapBranchToDir_RegexPerformance(t *testing.T) {
 		}
 	}
 }
+
+func TestRunHook(t *testing.T) {
+	dir := t.TempDir()
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PreCreate:  config.Commands("touch pre-create.txt"),
+			PreRemove:  config.Commands("false"),
+			PostRemove: config.Commands("false"),
+		},
+	}
+
+	if err := runHook(cfg, config.HookPreCreate, dir, CommandEnv{}, nil); err != nil {
+		t.Fatalf("pre-create hook failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pre-create.txt")); err != nil {
+		t.Errorf("pre-create hook did not run in %s: %v", dir, err)
+	}
+
+	if err := runHook(cfg, config.HookPreRemove, dir, CommandEnv{}, nil); err == nil {
+		t.Error("expected failing pre-remove hook to return an error")
+	}
+
+	// Events without commands are a no-op
+	if err := runHook(cfg, config.HookPostPrune, dir, CommandEnv{}, nil); err != nil {
+		t.Errorf("expected no error for unconfigured hook, got: %v", err)
+	}
+
+	// Post hooks only report failures
+	reportHook(cfg, config.HookPostRemove, dir, CommandEnv{}, nil)
+}
+
+func TestCommandEnvVars(t *testing.T) {
+	cmdEnv := CommandEnv{
+		Branch:        "feature/x",
+		Path:          "/repo.wt/feature-x",
+		RepoRoot:      "/repo",
+		Base:          "main",
+		DefaultBranch: "main",
+		IsNewBranch:   true,
+		DirName:       "feature-x",
+	}
+
+	want := []string{
+		"WT_BRANCH=feature/x",
+		"WT_PATH=/repo.wt/feature-x",
+		"WT_REPO_ROOT=/repo",
+		"WT_BASE=main",
+		"WT_DEFAULT_BRANCH=main",
+		"WT_IS_NEW_BRANCH=true",
+		"WT_DIR_NAME=feature-x",
+	}
+	got := cmdEnv.Vars()
+	if len(got) != len(want) {
+		t.Fatalf("Vars() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Vars()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// Inherited WT_* variables are overridden
+	t.Setenv("WT_BRANCH", "stale")
+	environ := cmdEnv.Environ()
+	last := ""
+	for _, kv := range environ {
+		if strings.HasPrefix(kv, "WT_BRANCH=") {
+			last = kv
+		}
+	}
+	if last != "WT_BRANCH=feature/x" {
+		t.Errorf("expected WT_BRANCH=feature/x to win, got %q", last)
+	}
+}
+
+func TestRunCommand_Structured(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// argv entries are passed literally and dir is relative to the worktree
+	c := config.Command{Argv: []string{"touch", "a b.txt"}, Dir: "sub"}
+	if err := runCommand(c, dir, os.Environ(), io.Discard); err != nil {
+		t.Fatalf("runCommand failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sub", "a b.txt")); err != nil {
+		t.Errorf("expected file with space in sub dir: %v", err)
+	}
+
+	// dir must stay inside the worktree
+	c = config.Command{Argv: []string{"true"}, Dir: "../outside"}
+	if err := runCommand(c, dir, os.Environ(), io.Discard); err == nil {
+		t.Error("expected error for dir escaping the worktree")
+	}
+
+	// timeout stops long-running commands
+	c = config.Command{Argv: []string{"sleep", "5"}, Timeout: "100ms"}
+	err := runCommand(c, dir, os.Environ(), io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got: %v", err)
+	}
+
+	// the timeout also kills the processes a command started, whose open
+	// output pipe would otherwise keep runCommand waiting until they exit
+	c = config.Command{Argv: []string{"sh", "-c", "sleep 30; true"}, Timeout: "200ms"}
+	start := time.Now()
+	err = runCommand(c, dir, os.Environ(), io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the timeout to stop the child process, took %s", elapsed)
+	}
+
+	// processes left running in the background do not hold up the command
+	c = config.Command{Argv: []string{"sh", "-c", "sleep 30 &"}}
+	start = time.Now()
+	if err := runCommand(c, dir, os.Environ(), io.Discard); err != nil {
+		t.Errorf("expected a command with a background process to succeed, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected not to wait for the background process, took %s", elapsed)
+	}
+
+	// quoted shell-word arguments work for string commands
+	c = config.Command{Line: `touch "quoted name.txt"`}
+	if err := runCommand(c, dir, os.Environ(), io.Discard); err != nil {
+		t.Fatalf("runCommand failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "quoted name.txt")); err != nil {
+		t.Errorf("expected quoted file name to be preserved: %v", err)
+	}
+}
+
+func TestRunCommands_Log(t *testing.T) {
+	dir := t.TempDir()
+	var logBuf bytes.Buffer
+
+	cmds := config.Commands("echo hello", "false", "echo unreachable")
+	err := runCommands("postCreateCmd", cmds, dir, os.Environ(), &logBuf)
+	if err == nil {
+		t.Fatal("expected failure from 'false'")
+	}
+
+	got := logBuf.String()
+	for _, want := range []string{"$ echo hello\nhello\n", "$ false\n", "# postCreateCmd 'false' failed"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected log to contain %q, got: %q", want, got)
+		}
+	}
+	if strings.Contains(got, "unreachable") {
+		t.Errorf("commands after a failure should not run, got: %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/trungung/wt/internal/config"
+	"github.com/trungung/wt/internal/git"
+)
+
+// Sources of the default branch, in the order DetectDefaultBranch tries them
+const (
+	DefaultBranchFromConfig       = "config"
+	DefaultBranchFromRemoteHead   = "remote HEAD"
+	DefaultBranchFromInitDefault  = "init.defaultBranch"
+	DefaultBranchFromLocalBranch  = "local branch"
+	DefaultBranchFromMainWorktree = "main worktree"
+)
+
+// defaultBranchNames are the local branches taken as the default branch, in
+// order, when neither the config, the remote nor init.defaultBranch names it
+var defaultBranchNames = []string{"main", "master", "trunk"}
+
+// DetectDefaultBranch returns the default branch of the repository at root
+// and where it was found, trying in order:
+//
+//  1. the defaultBranch config key
+//  2. <remote>/HEAD, as set by git clone or git remote set-head
+//  3. git's init.defaultBranch, if that branch exists locally
+//  4. the first of main, master and trunk that exists locally
+//  5. the branch checked out in the main worktree
+//
+// Only the first two are authoritative; the others are guesses for fresh
+// repositories, mirrors and clones without <remote>/HEAD. An error is
+// returned if nothing yields a branch, e.g. if the main worktree is detached.
+func DetectDefaultBranch(root string, cfg *config.Config) (string, string, error) {
+	if cfg.DefaultBranch != "" {
+		return cfg.DefaultBranch, DefaultBranchFromConfig, nil
+	}
+
+	remote := remoteName(cfg)
+	if branch, err := git.GetDefaultBranch(remote); err == nil {
+		return branch, DefaultBranchFromRemoteHead, nil
+	}
+
+	localExists := func(branch string) bool {
+		local, _ := git.BranchExists(branch, remote)
+		return local
+	}
+	if branch, err := git.GetConfig("init.defaultBranch"); err == nil && branch != "" && localExists(branch) {
+		return branch, DefaultBranchFromInitDefault, nil
+	}
+	for _, branch := range defaultBranchNames {
+		if localExists(branch) {
+			return branch, DefaultBranchFromLocalBranch, nil
+		}
+	}
+	if branch, err := git.GetCurrentBranchInMainWorktree(root); err == nil && branch != "" {
+		return branch, DefaultBranchFromMainWorktree, nil
+	}
+
+	return "", "", fmt.Errorf("could not determine default branch: %s/HEAD is not set, no main, master or trunk branch exists and the main worktree is detached; set 'defaultBranch' in config", remote)
+}
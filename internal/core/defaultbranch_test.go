@@ -0,0 +1,91 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/trungung/wt/internal/config"
+)
+
+func TestDetectDefaultBranch(t *testing.T) {
+	repo, runGit := initGitRepo(t, "work")
+
+	// Each step adds a source that takes precedence over the previous ones
+	tests := []struct {
+		name       string
+		setup      func()
+		cfg        config.Config
+		want       string
+		wantSource string
+	}{
+		{
+			name:  "nothing to go by",
+			setup: func() { runGit("checkout", "-q", "--detach") },
+		},
+		{
+			name:       "main worktree",
+			setup:      func() { runGit("checkout", "-q", "work") },
+			want:       "work",
+			wantSource: DefaultBranchFromMainWorktree,
+		},
+		{
+			name:       "local trunk",
+			setup:      func() { runGit("branch", "trunk") },
+			want:       "trunk",
+			wantSource: DefaultBranchFromLocalBranch,
+		},
+		{
+			name:       "master before trunk",
+			setup:      func() { runGit("branch", "master") },
+			want:       "master",
+			wantSource: DefaultBranchFromLocalBranch,
+		},
+		{
+			name:       "missing init.defaultBranch is skipped",
+			setup:      func() { runGit("config", "init.defaultBranch", "nope") },
+			want:       "master",
+			wantSource: DefaultBranchFromLocalBranch,
+		},
+		{
+			name:       "init.defaultBranch",
+			setup:      func() { runGit("config", "init.defaultBranch", "trunk") },
+			want:       "trunk",
+			wantSource: DefaultBranchFromInitDefault,
+		},
+		{
+			name: "remote HEAD",
+			setup: func() {
+				runGit("update-ref", "refs/remotes/upstream/dev", "HEAD")
+				runGit("symbolic-ref", "refs/remotes/upstream/HEAD", "refs/remotes/upstream/dev")
+			},
+			cfg:        config.Config{Remote: "upstream"},
+			want:       "dev",
+			wantSource: DefaultBranchFromRemoteHead,
+		},
+		{
+			name:       "config",
+			setup:      func() {},
+			cfg:        config.Config{Remote: "upstream", DefaultBranch: "release"},
+			want:       "release",
+			wantSource: DefaultBranchFromConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, source, err := DetectDefaultBranch(repo, &tt.cfg)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("DetectDefaultBranch() = %q (%s), want error", got, source)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectDefaultBranch() unexpected error: %v", err)
+			}
+			if got != tt.want || source != tt.wantSource {
+				t.Errorf("DetectDefaultBranch() = %q (%s), want %q (%s)", got, source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"os"
+	"strconv"
+)
+
+// CommandEnv describes the worktree a configured command (postCreateCmd or
+// hook) runs for. It is exported to the command as WT_* environment variables.
+type CommandEnv struct {
+	Branch        string
+	Path          string
+	RepoRoot      string
+	Base          string
+	DefaultBranch string
+	IsNewBranch   bool
+	DirName       string
+}
+
+// Vars returns the WT_* variables for this command environment
+func (e CommandEnv) Vars() []string {
+	return []string{
+		"WT_BRANCH=" + e.Branch,
+		"WT_PATH=" + e.Path,
+		"WT_REPO_ROOT=" + e.RepoRoot,
+		"WT_BASE=" + e.Base,
+		"WT_DEFAULT_BRANCH=" + e.DefaultBranch,
+		"WT_IS_NEW_BRANCH=" + strconv.FormatBool(e.IsNewBranch),
+		"WT_DIR_NAME=" + e.DirName,
+	}
+}
+
+// Environ returns the current process environment extended with Vars.
+// WT_* values take precedence over any inherited variables of the same name.
+func (e CommandEnv) Environ() []string {
+	return append(os.Environ(), e.Vars()...)
+}
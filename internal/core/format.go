@@ -0,0 +1,63 @@
+package core
+
+import (
+	"path/filepath"
+
+	"github.com/trungung/wt/internal/git"
+)
+
+// WorktreeInfo is the value `wt --format` templates are executed with. It
+// embeds git.Worktree, so .Path, .Branch, .Head, .Locked, .LockReason,
+// .Prunable and .PrunableReason are available as well.
+//
+// Fields are read from `git worktree list`. Dirty runs git, so it is only
+// evaluated when the template references it.
+type WorktreeInfo struct {
+	git.Worktree
+	// IsMain is true for the main worktree
+	IsMain bool
+	// DirName is the base name of the worktree directory
+	DirName string
+
+	dirty     bool
+	dirtyErr  error
+	dirtyDone bool
+}
+
+// ShortHead returns the abbreviated commit id checked out in the worktree
+func (w *WorktreeInfo) ShortHead() string {
+	if len(w.Head) > 7 {
+		return w.Head[:7]
+	}
+	return w.Head
+}
+
+// Dirty returns true if the worktree has uncommitted changes. Prunable
+// worktrees (whose directory is gone) are never dirty.
+func (w *WorktreeInfo) Dirty() (bool, error) {
+	if !w.dirtyDone {
+		if !w.Prunable {
+			w.dirty, w.dirtyErr = git.IsDirty(w.Path)
+		}
+		w.dirtyDone = true
+	}
+	return w.dirty, w.dirtyErr
+}
+
+// ListWorktreeInfos returns the worktrees in git's list order, the main
+// worktree first
+func ListWorktreeInfos() ([]*WorktreeInfo, error) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*WorktreeInfo, len(worktrees))
+	for i, wt := range worktrees {
+		infos[i] = &WorktreeInfo{
+			Worktree: wt,
+			IsMain:   i == 0,
+			DirName:  filepath.Base(wt.Path),
+		}
+	}
+	return infos, nil
+}
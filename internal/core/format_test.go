@@ -0,0 +1,47 @@
+package core
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/trungung/wt/internal/git"
+)
+
+func TestWorktreeInfo_Template(t *testing.T) {
+	// The path does not exist, so Dirty fails if it is evaluated
+	info := &WorktreeInfo{
+		Worktree: git.Worktree{
+			Path:   filepath.Join(t.TempDir(), "missing"),
+			Branch: "feature/x",
+			Head:   "0123456789abcdef0123456789abcdef01234567",
+			Locked: true,
+		},
+		DirName: "feature-x",
+	}
+
+	render := func(format string) (string, error) {
+		var buf bytes.Buffer
+		err := template.Must(template.New("test").Parse(format)).Execute(&buf, info)
+		return buf.String(), err
+	}
+
+	got, err := render("{{.Branch}} {{.ShortHead}} {{.DirName}}{{if .Locked}} locked{{end}}")
+	if err != nil {
+		t.Fatalf("unexpected error (Dirty evaluated?): %v", err)
+	}
+	if want := "feature/x 0123456 feature-x locked"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := render("{{if .Dirty}}*{{end}}"); err == nil {
+		t.Error("expected Dirty to fail for a missing worktree directory")
+	}
+
+	// Prunable worktrees are not inspected
+	info = &WorktreeInfo{Worktree: git.Worktree{Path: info.Path, Prunable: true}}
+	if got, err := render("{{.Dirty}}"); err != nil || got != "false" {
+		t.Errorf("expected prunable worktree to be clean, got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// sameFilesystem returns true if both existing paths are on the same device
+func sameFilesystem(a, b string) (bool, error) {
+	devA, err := deviceOf(a)
+	if err != nil {
+		return false, err
+	}
+	devB, err := deviceOf(b)
+	if err != nil {
+		return false, err
+	}
+	return devA == devB, nil
+}
+
+// deviceOf returns the device id of path
+func deviceOf(path string) (uint64, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("cannot determine filesystem of %s", path)
+	}
+	return uint64(st.Dev), nil
+}
@@ -2,9 +2,12 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 
 	"github.com/trungung/wt/internal/config"
 	"github.com/trungung/wt/internal/git"
@@ -24,9 +27,21 @@ const (
 
 // HealthCheck represents a single health check result
 type HealthCheck struct {
-	Name    string
-	Level   HealthLevel
-	Message string
+	Name    string      `json:"name"`
+	Level   HealthLevel `json:"level"`
+	Message string      `json:"message"`
+	// Fix describes the change ApplyFix makes to resolve the issue; empty if
+	// it cannot be fixed automatically
+	Fix string `json:"fix,omitempty"`
+	fix func() error
+}
+
+// ApplyFix makes the change described by Fix
+func (c HealthCheck) ApplyFix() error {
+	if c.fix == nil {
+		return fmt.Errorf("%s: no automatic fix", c.Name)
+	}
+	return c.fix()
 }
 
 // RunHealthCheck performs a comprehensive health check of the wt setup
@@ -40,6 +55,12 @@ func RunHealthCheck() ([]HealthCheck, bool) {
 			hasError = true
 		}
 	}
+	// addFixable adds an issue that fixFn resolves as described by fix
+	addFixable := func(name string, level HealthLevel, message, fix string, fixFn func() error) {
+		add(name, level, message)
+		checks[len(checks)-1].Fix = fix
+		checks[len(checks)-1].fix = fixFn
+	}
 
 	// 1. Repo root
 	root, err := git.GetRepoRoot()
@@ -52,6 +73,8 @@ func RunHealthCheck() ([]HealthCheck, bool) {
 	// 2. Config validity
 	configPath := config.GetConfigPath(root)
 	var cfg *config.Config
+	// Only a config that loads is edited by fixes; anything else needs a human
+	cfgEditable := true
 	if _, err := os.Stat(configPath); err == nil {
 		data, err := os.ReadFile(configPath)
 		if err != nil {
@@ -63,7 +86,9 @@ func RunHealthCheck() ([]HealthCheck, bool) {
 			} else {
 				unknown, _ := config.CheckUnknownKeys(root)
 				if len(unknown) > 0 {
-					add("Config", LevelWarn, fmt.Sprintf("unknown keys: %v", unknown))
+					addFixable("Config", LevelWarn, fmt.Sprintf("unknown keys: %v", unknown),
+						fmt.Sprintf("delete unknown keys %v from %s", unknown, configPath),
+						func() error { return config.RemoveKeys(root, unknown) })
 				} else {
 					add("Config", LevelOk, "valid")
 				}
@@ -72,8 +97,14 @@ func RunHealthCheck() ([]HealthCheck, bool) {
 		var loadErr error
 		cfg, loadErr = config.LoadConfig(root)
 		if loadErr != nil {
-			// If LoadConfig fails, we use a blank config for the rest of the checks
-			// to avoid panics, but the ERROR is already reported above.
+			cfgEditable = false
+			// Syntax errors are already reported above; anything else is a
+			// well-formed file with invalid values (e.g. a malformed command).
+			var syntaxErr *json.SyntaxError
+			if !errors.As(loadErr, &syntaxErr) && data != nil {
+				add("Config", LevelError, fmt.Sprintf("invalid value: %v", loadErr))
+			}
+			// Use a blank config for the rest of the checks to avoid panics
 			cfg = &config.Config{}
 		}
 	} else {
@@ -81,18 +112,69 @@ func RunHealthCheck() ([]HealthCheck, bool) {
 		cfg = &config.Config{}
 	}
 
-	// 3. Default branch
-	defaultBranch := cfg.DefaultBranch
-	if defaultBranch == "" {
-		detected, err := git.GetDefaultBranch()
+	// Trust of repository-defined commands
+	if cfg.HasCommands() {
+		trusted, err := IsConfigTrusted(&RepoEnv{Root: root, Config: cfg})
 		if err != nil {
-			add("Default branch", LevelError, "could not determine default branch via origin/HEAD. Please set 'defaultBranch' in config.")
+			add("Trust", LevelWarn, fmt.Sprintf("could not check trust: %v", err))
+		} else if trusted {
+			add("Trust", LevelOk, "commands approved")
 		} else {
-			defaultBranch = detected
-			add("Default branch", LevelOk, defaultBranch)
+			add("Trust", LevelWarn, "commands not approved; review them and run 'wt trust'")
 		}
-	} else {
+	}
+
+	// Remote holding the canonical branches
+	remote := remoteName(cfg)
+	remotes, _ := git.ListRemotes()
+	remoteExists := slices.Contains(remotes, remote)
+	switch {
+	case remoteExists:
+		add("Remote", LevelOk, remote)
+	case remote == config.DefaultRemote && cfg.Remote == "" && remoteOverride == "":
+		add("Remote", LevelOk, fmt.Sprintf("%s (not configured in git; remote branches are not used)", remote))
+	default:
+		add("Remote", LevelWarn, fmt.Sprintf("remote %q does not exist (remotes: %v); fix 'remote' in config or --remote", remote, remotes))
+	}
+
+	// 3. Default branch, and which of DetectDefaultBranch's sources gave it.
+	// Anything but the config or <remote>/HEAD is a guess worth pinning down.
+	defaultBranch, source, err := DetectDefaultBranch(root, cfg)
+	setHead := func() error {
+		if err := git.UpdateRemoteHead(remote); err != nil {
+			return err
+		}
+		_, err := git.GetDefaultBranch(remote)
+		return err
+	}
+	setHeadFix := fmt.Sprintf("run 'git remote set-head %s --auto' to ask %s for its default branch", remote, remote)
+	switch {
+	case err != nil:
+		if remoteExists {
+			addFixable("Default branch", LevelError, err.Error(), setHeadFix, setHead)
+		} else {
+			add("Default branch", LevelError, err.Error())
+		}
+	case source == DefaultBranchFromConfig:
 		add("Default branch", LevelOk, fmt.Sprintf("%s (override)", defaultBranch))
+	case source == DefaultBranchFromRemoteHead:
+		add("Default branch", LevelOk, fmt.Sprintf("%s (from %s/HEAD)", defaultBranch, remote))
+	default:
+		message := fmt.Sprintf("%s (guessed from %s; %s/HEAD is not set)", defaultBranch, source, remote)
+		if remoteExists {
+			addFixable("Default branch", LevelWarn, message, setHeadFix, setHead)
+		} else if cfgEditable {
+			branch := defaultBranch
+			addFixable("Default branch", LevelWarn, message,
+				fmt.Sprintf("write \"defaultBranch\": %q to %s", branch, configPath),
+				func() error {
+					return config.Edit(root, func(raw map[string]interface{}) {
+						raw["defaultBranch"] = branch
+					})
+				})
+		} else {
+			add("Default branch", LevelWarn, message)
+		}
 	}
 
 	// 4. Worktree base directory writability
@@ -161,5 +243,144 @@ func RunHealthCheck() ([]HealthCheck, bool) {
 		}
 	}
 
+	// 7. Links between worktree directories and git's records of them. Fixes
+	// are applied in check order, so moved worktrees are repaired before the
+	// dangling records below are pruned.
+	env := &RepoEnv{Root: root, Config: cfg, DefaultBranch: defaultBranch, Remote: remote}
+	broken, err := BrokenLinks(env)
+	switch {
+	case err != nil:
+		add("Worktree links", LevelWarn, fmt.Sprintf("could not check: %v", err))
+	case len(broken) == 0:
+		add("Worktree links", LevelOk, "all worktrees linked")
+	default:
+		dirs := make([]string, 0, len(broken))
+		for dir := range broken {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			addFixable("Worktree links", LevelError, fmt.Sprintf("%s: %s; run 'wt repair'", dir, broken[dir]),
+				fmt.Sprintf("run 'git worktree repair %s'", dir),
+				func() error {
+					return withRepoLock(env, func() error {
+						_, err := git.RepairWorktree(dir)
+						return err
+					})
+				})
+		}
+	}
+
+	// 8-10. Compare the worktree base with git's worktree records
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		add("Worktrees", LevelWarn, fmt.Sprintf("could not list worktrees: %v", err))
+		return checks, hasError
+	}
+
+	orphans, err := orphanDirs(root, wtRoot, worktrees)
+	switch {
+	case err != nil:
+		add("Orphaned directories", LevelWarn, fmt.Sprintf("could not check: %v", err))
+	case len(orphans) == 0:
+		add("Orphaned directories", LevelOk, "none")
+	default:
+		for _, dir := range orphans {
+			addFixable("Orphaned directories", LevelWarn, fmt.Sprintf("%s is not a worktree git knows about (e.g. left over from a failed rollback); delete it if it holds nothing you need", dir),
+				fmt.Sprintf("delete directory %s and everything in it", dir),
+				func() error { return removeOrphanDir(env, wtRoot, dir) })
+		}
+	}
+
+	var dangling, outside []git.Worktree
+	for i, wt := range worktrees {
+		if i == 0 || wt.Bare {
+			continue
+		}
+		if wt.Prunable {
+			dangling = append(dangling, wt)
+		} else if evalPath(filepath.Dir(wt.Path)) != evalPath(wtRoot) {
+			outside = append(outside, wt)
+		}
+	}
+	if len(dangling) == 0 {
+		add("Dangling worktrees", LevelOk, "none")
+	}
+	for _, wt := range dangling {
+		addFixable("Dangling worktrees", LevelWarn, fmt.Sprintf("%s is registered but %s is missing (%s); run 'wt repair' to prune it, or 'wt remove %s'",
+			worktreeLabel(wt), wt.Path, wt.PrunableReason, wt.Path),
+			fmt.Sprintf("run 'git worktree prune' to delete the record of %s (the branch is kept)", wt.Path),
+			func() error {
+				return withRepoLock(env, git.PruneWorktreeRecords)
+			})
+	}
+	if len(outside) == 0 {
+		add("Worktree locations", LevelOk, fmt.Sprintf("all in %s", wtRoot))
+	}
+	for _, wt := range outside {
+		add("Worktree locations", LevelWarn, fmt.Sprintf("%s is at %s, outside the worktree base %s; run 'wt mv --to-template' to move it",
+			worktreeLabel(wt), wt.Path, wtRoot))
+	}
+
 	return checks, hasError
 }
+
+// removeOrphanDir deletes an orphaned directory found by orphanDirs, holding
+// its directory lock so a worktree being created there is not deleted
+func removeOrphanDir(env *RepoEnv, base, dir string) error {
+	unlock, err := lockDirs(env, filepath.Base(dir))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	orphans, err := orphanDirs(env.Root, base, worktrees)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(orphans, dir) {
+		return fmt.Errorf("%s is no longer an orphaned directory", dir)
+	}
+	return os.RemoveAll(dir)
+}
+
+// orphanDirs returns the directories in the worktree base that git has no
+// worktree record for and that hold no repository at all, e.g. left over
+// when a worktree was created or removed only partly. Directories with a
+// .git entry are either worktrees with broken links (see BrokenLinks) or
+// belong to other repositories sharing the base.
+func orphanDirs(root, base string, worktrees []git.Worktree) ([]string, error) {
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	registered := make(map[string]bool)
+	for _, wt := range worktrees {
+		registered[evalPath(wt.Path)] = true
+	}
+	registered[evalPath(root)] = true
+
+	var orphans []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(base, e.Name())
+		if registered[evalPath(dir)] {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			continue
+		}
+		orphans = append(orphans, dir)
+	}
+	return orphans, nil
+}
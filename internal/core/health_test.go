@@ -0,0 +1,51 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/trungung/wt/internal/git"
+)
+
+func TestOrphanDirs(t *testing.T) {
+	tmp := t.TempDir()
+	root := filepath.Join(tmp, "repo")
+	base := filepath.Join(tmp, "repo.wt")
+	mkdir := func(path string) string {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	registered := mkdir(filepath.Join(base, "feature-x"))
+	orphan := mkdir(filepath.Join(base, "leftover"))
+	brokenLink := mkdir(filepath.Join(base, "moved"))
+	if err := os.WriteFile(filepath.Join(brokenLink, ".git"), []byte("gitdir: /nowhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mkdir(filepath.Join(base, "clone", ".git"))
+	if err := os.WriteFile(filepath.Join(base, "notes.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees := []git.Worktree{
+		{Path: root, Branch: "main"},
+		{Path: registered, Branch: "feature/x"},
+		{Path: filepath.Join(base, "gone"), Branch: "gone", Prunable: true},
+	}
+	got, err := orphanDirs(root, base, worktrees)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{orphan}; !slices.Equal(got, want) {
+		t.Errorf("orphanDirs() = %v, want %v", got, want)
+	}
+
+	got, err = orphanDirs(root, filepath.Join(tmp, "missing"), worktrees)
+	if err != nil || len(got) != 0 {
+		t.Errorf("orphanDirs() of a missing base = %v, %v; want none", got, err)
+	}
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// initGitRepo creates a repository with an empty commit on branch, isolated
+// from the user's git config, and changes into it. The returned function runs
+// git there.
+func initGitRepo(t *testing.T, branch string) (string, func(args ...string)) {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "wt")
+	t.Setenv("GIT_AUTHOR_EMAIL", "wt@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "wt")
+	t.Setenv("GIT_COMMITTER_EMAIL", "wt@example.com")
+
+	repo := t.TempDir()
+	t.Chdir(repo)
+	runGit := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s: %v", args, out, err)
+		}
+	}
+	runGit("init", "-q", "-b", branch)
+	runGit("commit", "-q", "--allow-empty", "-m", "initial")
+	return repo, runGit
+}
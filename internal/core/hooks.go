@@ -0,0 +1,40 @@
+package core
+
+import (
+	"io"
+
+	"github.com/trungung/wt/internal/config"
+	"github.com/trungung/wt/internal/log"
+)
+
+// runHook executes the commands configured for a lifecycle event in dir.
+// Commands see cmdEnv as WT_* variables plus WT_HOOK set to the event name.
+// The caller decides what a failure means: pre-* hooks abort the operation,
+// post-* hooks should be reported via reportHook. Output is also written to
+// logFile unless it is nil.
+func runHook(cfg *config.Config, event, dir string, cmdEnv CommandEnv, logFile io.Writer) error {
+	cmds := cfg.Hooks.Commands(event)
+	if len(cmds) == 0 {
+		return nil
+	}
+	log.Debugf("running %s hook in %s", event, dir)
+	return runCommands(event+" hook", cmds, dir, append(cmdEnv.Environ(), "WT_HOOK="+event), logFile)
+}
+
+// reportHook runs a post-* hook and logs a warning if it fails. The operation
+// it follows has already completed, so the failure is not propagated.
+func reportHook(cfg *config.Config, event, dir string, cmdEnv CommandEnv, logFile io.Writer) {
+	if err := runHook(cfg, event, dir, cmdEnv, logFile); err != nil {
+		log.Warnf("%v", err)
+	}
+}
+
+// hasHooks returns true if cfg defines commands for any of the given events
+func hasHooks(cfg *config.Config, events ...string) bool {
+	for _, event := range events {
+		if len(cfg.Hooks.Commands(event)) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/trungung/wt/internal/git"
+)
+
+// LockWorktree locks the worktree addressed by target (see ResolveWorktree)
+// with `git worktree lock`. Locked worktrees are skipped by PruneWorktrees
+// and refused by RemoveWorktree unless the lock is explicitly ignored.
+func LockWorktree(target, reason string) (git.Worktree, error) {
+	return changeWorktreeLock(target, func(wt git.Worktree) error {
+		if wt.Locked {
+			return fmt.Errorf("worktree %s is already %s", worktreeLabel(wt), DescribeLock(wt))
+		}
+		return git.LockWorktree(wt.Path, reason)
+	})
+}
+
+// UnlockWorktree removes the lock set by LockWorktree
+func UnlockWorktree(target string) (git.Worktree, error) {
+	return changeWorktreeLock(target, func(wt git.Worktree) error {
+		if !wt.Locked {
+			return fmt.Errorf("worktree %s is not locked", worktreeLabel(wt))
+		}
+		return git.UnlockWorktree(wt.Path)
+	})
+}
+
+// changeWorktreeLock resolves target and runs fn on it under the repository lock
+func changeWorktreeLock(target string, fn func(wt git.Worktree) error) (git.Worktree, error) {
+	env, err := LoadRepoEnv()
+	if err != nil {
+		return git.Worktree{}, err
+	}
+
+	var wt git.Worktree
+	err = withRepoLock(env, func() error {
+		var isMain bool
+		wt, isMain, err = ResolveWorktree(target)
+		if err != nil {
+			return err
+		}
+		if isMain {
+			return fmt.Errorf("the main worktree cannot be locked")
+		}
+		return fn(wt)
+	})
+	return wt, err
+}
+
+// DescribeLock describes a locked worktree: "locked" or "locked: <reason>"
+func DescribeLock(wt git.Worktree) string {
+	if wt.LockReason == "" {
+		return "locked"
+	}
+	return "locked: " + wt.LockReason
+}
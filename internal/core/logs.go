@@ -0,0 +1,124 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/trungung/wt/internal/git"
+	"github.com/trungung/wt/internal/log"
+)
+
+// PostCreateLogName is the log file that records the output of the commands
+// run while creating a worktree (pre-create, postCreateCmd, post-create)
+const PostCreateLogName = "post-create.log"
+
+// accessStampName is the empty file next to the logs whose modification time
+// records when wt last returned the worktree (see recordAccess)
+const accessStampName = "last-access"
+
+// logDir returns the directory holding the logs for a worktree directory name:
+// <git-common-dir>/wt/logs/<dir-name>. It is shared by all worktrees.
+func logDir(repoRoot, dirName string) (string, error) {
+	commonDir, err := git.GetCommonDir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, "wt", "logs", dirName), nil
+}
+
+// LogPath returns the path of the post-create log for the worktree addressed
+// by target (see ResolveWorktree), or for a branch without a worktree, e.g.
+// after a rollback. The file exists only once a worktree was created.
+func LogPath(target string) (string, error) {
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return "", err
+	}
+	var dirName string
+	if wt, _, err := ResolveWorktree(target); err == nil {
+		dirName = filepath.Base(wt.Path)
+	} else if dirName, err = MapBranchToDir(target); err != nil {
+		return "", err
+	}
+	dir, err := logDir(root, dirName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, PostCreateLogName), nil
+}
+
+// createLog truncates and opens the post-create log for a new worktree.
+// It returns nil (and logs a warning) if the file cannot be created, since
+// output is still shown on stderr.
+func createLog(repoRoot, dirName, branch string) io.WriteCloser {
+	dir, err := logDir(repoRoot, dirName)
+	if err == nil {
+		err = os.MkdirAll(dir, 0755)
+	}
+	var f *os.File
+	if err == nil {
+		f, err = os.Create(filepath.Join(dir, PostCreateLogName))
+	}
+	if err != nil {
+		log.Warnf("cannot write post-create log: %v", err)
+		return nil
+	}
+	_, _ = fmt.Fprintf(f, "# wt: creating worktree for %s at %s\n", branch, time.Now().Format(time.RFC3339))
+	return f
+}
+
+// commandOutput returns where a command's stdout and stderr go: always stderr,
+// so that stdout carries only wt's own result (e.g. the worktree path), and
+// additionally logFile when set.
+func commandOutput(logFile io.Writer) io.Writer {
+	if logFile == nil {
+		return os.Stderr
+	}
+	return io.MultiWriter(os.Stderr, logFile)
+}
+
+// recordAccess notes that wt returned the linked worktree at path, e.g. for
+// wt <branch>, wt cd or wt run, for wt prune --inactive-for. Failures only
+// make the worktree look less recently used, so they are not errors.
+func recordAccess(path string) {
+	root, err := git.GetRepoRoot()
+	if err != nil || evalPath(root) == evalPath(path) {
+		return
+	}
+	dir, err := logDir(root, filepath.Base(path))
+	if err == nil {
+		err = os.MkdirAll(dir, 0755)
+	}
+	if err != nil {
+		log.Debugf("failed to record access to %s: %v", path, err)
+		return
+	}
+	stamp := filepath.Join(dir, accessStampName)
+	now := time.Now()
+	if err := os.Chtimes(stamp, now, now); err == nil {
+		return
+	}
+	f, err := os.Create(stamp)
+	if err != nil {
+		log.Debugf("failed to record access to %s: %v", path, err)
+		return
+	}
+	_ = f.Close()
+}
+
+// lastAccess returns when wt last returned the worktree directory dirName,
+// or the zero time if it has no record of it
+func lastAccess(repoRoot, dirName string) time.Time {
+	dir, err := logDir(repoRoot, dirName)
+	if err != nil {
+		return time.Time{}
+	}
+	fi, err := os.Stat(filepath.Join(dir, accessStampName))
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
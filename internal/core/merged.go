@@ -0,0 +1,133 @@
+package core
+
+import (
+	"github.com/trungung/wt/internal/config"
+	"github.com/trungung/wt/internal/git"
+)
+
+// Rules by which PruneWorktrees selects a worktree, as reported in
+// PruneEntry.Rule. All but PruneRuleGone are merge rules (see mergeDetector).
+const (
+	// PruneRuleMerged: the branch tip is an ancestor of the default branch
+	PruneRuleMerged = "merged"
+	// PruneRuleSameTree: a commit on the default branch has the branch's tree
+	PruneRuleSameTree = "same-tree"
+	// PruneRuleRebased: every commit of the branch has an equivalent (same
+	// patch id) on the default branch
+	PruneRuleRebased = "rebased"
+	// PruneRuleSquashed: a commit on the default branch makes the same
+	// change as all commits of the branch together
+	PruneRuleSquashed = "squashed"
+	// PruneRuleGone: the branch's upstream was deleted on the remote
+	PruneRuleGone = "gone"
+)
+
+// isMergeRule reports whether rule found the branch's changes on the default
+// branch, so deleting the branch loses no work
+func isMergeRule(rule string) bool {
+	switch rule {
+	case PruneRuleMerged, PruneRuleSameTree, PruneRuleRebased, PruneRuleSquashed:
+		return true
+	}
+	return false
+}
+
+// mergeDetector decides whether branches are merged into base, using the
+// rules a prune strategy (see config.PruneStrategies) allows
+type mergeDetector struct {
+	base     string
+	strategy string
+	// ancestors are the branches whose tip is in base
+	ancestors map[string]bool
+	// patchIDs caches the patch ids of the commits on base since a merge base
+	patchIDs map[string]map[string]bool
+}
+
+func newMergeDetector(base, strategy string) (*mergeDetector, error) {
+	if err := config.ValidatePruneStrategy(strategy); err != nil {
+		return nil, err
+	}
+	merged, err := git.GetMergedBranches(base)
+	if err != nil {
+		return nil, err
+	}
+	d := &mergeDetector{
+		base:      base,
+		strategy:  strategy,
+		ancestors: make(map[string]bool),
+		patchIDs:  make(map[string]map[string]bool),
+	}
+	for _, b := range merged {
+		d.ancestors[b] = true
+	}
+	return d, nil
+}
+
+// rule returns the rule by which branch counts as merged into base, or "" if
+// it does not. The cheap ancestry check comes first; the others only run for
+// the rebase and squash strategies.
+func (d *mergeDetector) rule(branch string) (string, error) {
+	if d.ancestors[branch] {
+		return PruneRuleMerged, nil
+	}
+	if d.strategy == config.PruneStrategyAncestry {
+		return "", nil
+	}
+
+	mergeBase, err := git.MergeBase(d.base, branch)
+	if err != nil {
+		return "", nil // Unrelated histories: nothing was merged
+	}
+	since := mergeBase + ".." + d.base
+
+	// A rebase merge (or a merge of the rebased branch) leaves a commit on
+	// base with exactly the branch's files
+	tree, err := git.GetTree(branch)
+	if err != nil {
+		return "", err
+	}
+	trees, err := git.ListTrees(since)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range trees {
+		if t == tree {
+			return PruneRuleSameTree, nil
+		}
+	}
+
+	// Commits rebased or cherry-picked one by one keep their patch ids
+	total, unpicked, err := git.CountUnpicked(d.base, branch)
+	if err != nil {
+		return "", err
+	}
+	if total > 0 && unpicked == 0 {
+		return PruneRuleRebased, nil
+	}
+
+	if d.strategy != config.PruneStrategySquash {
+		return "", nil
+	}
+
+	// A squash merge is one commit with the branch's cumulative diff
+	patchID, err := git.DiffPatchID(mergeBase, branch)
+	if err != nil || patchID == "" {
+		return "", err
+	}
+	ids, ok := d.patchIDs[mergeBase]
+	if !ok {
+		list, err := git.ListPatchIDs(since)
+		if err != nil {
+			return "", err
+		}
+		ids = make(map[string]bool, len(list))
+		for _, id := range list {
+			ids[id] = true
+		}
+		d.patchIDs[mergeBase] = ids
+	}
+	if ids[patchID] {
+		return PruneRuleSquashed, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"os"
+	"testing"
+
+	"github.com/trungung/wt/internal/config"
+)
+
+func TestMergeDetector(t *testing.T) {
+	_, runGit := initGitRepo(t, "main")
+	commit := func(file string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(file+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit("add", file)
+		runGit("commit", "-q", "-m", file)
+	}
+	commit("base")
+
+	// merged: merged with a merge commit
+	runGit("checkout", "-q", "-b", "merged", "main")
+	commit("m1")
+	runGit("checkout", "-q", "main")
+	runGit("merge", "-q", "--no-ff", "-m", "merge", "merged")
+
+	// rebased: its commits cherry-picked onto main
+	runGit("checkout", "-q", "-b", "rebased", "main~1")
+	commit("r1")
+	commit("r2")
+	runGit("checkout", "-q", "main")
+	runGit("cherry-pick", "main..rebased")
+
+	// squashed: both commits squashed into one on main
+	runGit("checkout", "-q", "-b", "squashed", "main~1")
+	commit("s1")
+	commit("s2")
+	runGit("checkout", "-q", "main")
+	runGit("merge", "-q", "--squash", "squashed")
+	runGit("commit", "-q", "-m", "squash")
+
+	// same-tree: squashed onto main's tip, so main got the exact tree
+	runGit("checkout", "-q", "-b", "same-tree", "main")
+	commit("t1")
+	commit("t2")
+	runGit("checkout", "-q", "main")
+	runGit("merge", "-q", "--squash", "same-tree")
+	runGit("commit", "-q", "-m", "squash same-tree")
+
+	// partial: only one of its commits is on main
+	runGit("checkout", "-q", "-b", "partial", "main~1")
+	commit("p1")
+	runGit("checkout", "-q", "main")
+	runGit("cherry-pick", "partial")
+	runGit("checkout", "-q", "partial")
+	commit("p2")
+
+	runGit("checkout", "-q", "-b", "unmerged", "main")
+	commit("u1")
+	runGit("checkout", "-q", "main")
+
+	want := map[string]map[string]string{
+		config.PruneStrategyAncestry: {
+			"merged": PruneRuleMerged,
+		},
+		config.PruneStrategyRebase: {
+			"merged":    PruneRuleMerged,
+			"rebased":   PruneRuleRebased,
+			"same-tree": PruneRuleSameTree,
+		},
+		config.PruneStrategySquash: {
+			"merged":    PruneRuleMerged,
+			"rebased":   PruneRuleRebased,
+			"same-tree": PruneRuleSameTree,
+			"squashed":  PruneRuleSquashed,
+		},
+	}
+	branches := []string{"merged", "rebased", "squashed", "same-tree", "partial", "unmerged"}
+
+	for _, strategy := range config.PruneStrategies {
+		t.Run(strategy, func(t *testing.T) {
+			d, err := newMergeDetector("main", strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, branch := range branches {
+				got, err := d.rule(branch)
+				if err != nil {
+					t.Fatalf("rule(%q) unexpected error: %v", branch, err)
+				}
+				if got != want[strategy][branch] {
+					t.Errorf("rule(%q) = %q, want %q", branch, got, want[strategy][branch])
+				}
+			}
+		})
+	}
+
+	if _, err := newMergeDetector("main", "nope"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
@@ -0,0 +1,319 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/trungung/wt/internal/git"
+	"github.com/trungung/wt/internal/log"
+)
+
+// MoveOptions controls MoveWorktree and MoveToTemplate
+type MoveOptions struct {
+	// Force moves dirty worktrees to another filesystem
+	Force bool
+	// IgnoreLock moves locked worktrees too (see LockWorktree)
+	IgnoreLock bool
+	// DryRun only reports the moves MoveToTemplate would make
+	DryRun bool
+}
+
+// MoveEntry is a worktree considered by MoveToTemplate
+type MoveEntry struct {
+	Branch string `json:"branch"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	// Reason explains why a worktree was not moved; empty for moved worktrees
+	Reason string `json:"reason,omitempty"`
+}
+
+// MoveResult lists the worktrees that were moved (or, in a dry run, would be
+// moved) to their template location and those that were skipped.
+type MoveResult struct {
+	Moved   []MoveEntry
+	Skipped []MoveEntry
+}
+
+// MoveWorktree moves the worktree addressed by target (see ResolveWorktree)
+// to newPath, or to the location worktreePathTemplate computes for it if
+// newPath is empty. If newPath is an existing directory, the worktree is
+// moved into it. It returns the new path.
+func MoveWorktree(target, newPath string, opts MoveOptions) (string, error) {
+	env, err := LoadRepoEnv()
+	if err != nil {
+		return "", err
+	}
+
+	wt, isMain, err := ResolveWorktree(target)
+	if err != nil {
+		return "", err
+	}
+	if isMain {
+		return "", fmt.Errorf("the main worktree cannot be moved")
+	}
+
+	if newPath == "" {
+		if newPath, err = templatePath(env, wt); err != nil {
+			return "", err
+		}
+	} else {
+		if newPath, err = filepath.Abs(newPath); err != nil {
+			return "", err
+		}
+		if fi, err := os.Stat(newPath); err == nil && fi.IsDir() {
+			newPath = filepath.Join(newPath, filepath.Base(wt.Path))
+		}
+	}
+
+	if evalPath(wt.Path) == evalPath(newPath) {
+		return "", fmt.Errorf("worktree %s is already at %s", worktreeLabel(wt), newPath)
+	}
+	if err := moveWorktree(env, wt, newPath, opts); err != nil {
+		return "", err
+	}
+	return newPath, nil
+}
+
+// MoveToTemplate moves every linked worktree that is not where the current
+// worktreePathTemplate places it. Worktrees that cannot be moved are skipped
+// with the reason.
+func MoveToTemplate(opts MoveOptions) (*MoveResult, error) {
+	env, err := LoadRepoEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MoveResult{}
+	for i, wt := range worktrees {
+		if i == 0 || wt.Bare {
+			continue // The main worktree stays where it is
+		}
+
+		entry := MoveEntry{Branch: wt.Branch, From: wt.Path}
+		to, err := templatePath(env, wt)
+		if err != nil {
+			entry.Reason = err.Error()
+			result.Skipped = append(result.Skipped, entry)
+			continue
+		}
+		entry.To = to
+		if evalPath(wt.Path) == evalPath(to) {
+			continue
+		}
+
+		if opts.DryRun {
+			err = checkMovable(wt, to, opts)
+		} else {
+			err = moveWorktree(env, wt, to, opts)
+		}
+		if err != nil {
+			log.Debugf("not moving %s: %v", wt.Path, err)
+			entry.Reason = err.Error()
+			result.Skipped = append(result.Skipped, entry)
+			continue
+		}
+		result.Moved = append(result.Moved, entry)
+	}
+	return result, nil
+}
+
+// templatePath returns where worktreePathTemplate places wt. Detached
+// worktrees keep their directory name.
+func templatePath(env *RepoEnv, wt git.Worktree) (string, error) {
+	dirName := filepath.Base(wt.Path)
+	if !wt.Detached {
+		var err error
+		if dirName, err = MapBranchToDir(wt.Branch); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Clean(filepath.Join(env.Config.GetWorktreeBase(env.Root), dirName)), nil
+}
+
+// checkMovable returns why wt cannot be moved to newPath, if anything
+func checkMovable(wt git.Worktree, newPath string, opts MoveOptions) error {
+	label := worktreeLabel(wt)
+	if wt.Prunable {
+		return fmt.Errorf("worktree %s is prunable (its directory is gone); use wt remove", label)
+	}
+	if wt.Locked && !opts.IgnoreLock {
+		return fmt.Errorf("worktree %s is %s; use --ignore-lock or wt unlock", label, DescribeLock(wt))
+	}
+	if _, err := os.Lstat(newPath); err == nil {
+		return fmt.Errorf("destination %s already exists", newPath)
+	}
+
+	if opts.Force {
+		return nil
+	}
+	same, err := sameFilesystem(wt.Path, existingParent(newPath))
+	if err != nil {
+		return err
+	}
+	if !same {
+		dirty, err := git.IsDirty(wt.Path)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("worktree %s is dirty and %s is on another filesystem; use --force", label, newPath)
+		}
+	}
+	return nil
+}
+
+// moveWorktree moves wt to newPath while holding the directory locks of the
+// old and new directory names
+func moveWorktree(env *RepoEnv, wt git.Worktree, newPath string, opts MoveOptions) error {
+	unlock, err := lockDirs(env, filepath.Base(wt.Path), filepath.Base(newPath))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return relocate(env, wt, newPath, opts)
+}
+
+// lockDirs acquires the directory locks for dirNames in a fixed order, so two
+// operations locking the same names cannot deadlock. The returned function
+// releases them.
+func lockDirs(env *RepoEnv, dirNames ...string) (func(), error) {
+	names := slices.Clone(dirNames)
+	sort.Strings(names)
+	names = slices.Compact(names)
+
+	var unlocks []func() error
+	release := func() {
+		for _, unlock := range unlocks {
+			_ = unlock()
+		}
+	}
+	for _, name := range names {
+		unlock, err := git.AcquireDirLock(env.Root, name, lockTimeout(env.Config), nil)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		unlocks = append(unlocks, unlock)
+	}
+	return release, nil
+}
+
+// relocate moves wt to newPath; the caller holds the directory locks. Within
+// a filesystem it uses git worktree move; across filesystems it copies the
+// worktree, repairs git's record of it and deletes the original.
+func relocate(env *RepoEnv, wt git.Worktree, newPath string, opts MoveOptions) error {
+	if err := checkMovable(wt, newPath, opts); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	same, err := sameFilesystem(wt.Path, filepath.Dir(newPath))
+	if err != nil {
+		return err
+	}
+	if same {
+		return withRepoLock(env, func() error {
+			return git.MoveWorktree(wt.Path, newPath, wt.Locked)
+		})
+	}
+
+	if err := copyTree(wt.Path, newPath); err != nil {
+		_ = os.RemoveAll(newPath)
+		return fmt.Errorf("failed to copy worktree to %s: %w", newPath, err)
+	}
+	if err := withRepoLock(env, func() error {
+		_, err := git.RepairWorktree(newPath)
+		return err
+	}); err != nil {
+		_ = os.RemoveAll(newPath)
+		return err
+	}
+	if err := os.RemoveAll(wt.Path); err != nil {
+		log.Warnf("moved worktree to %s but failed to delete %s: %v", newPath, wt.Path, err)
+	}
+	return nil
+}
+
+// existingParent returns path or its closest existing parent directory
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// copyTree copies the directory src to dst, which must not exist. Regular
+// files keep their mode and modification time; symlinks are recreated as is.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			return os.Chtimes(target, info.ModTime(), info.ModTime())
+		default:
+			return fmt.Errorf("cannot copy %s: unsupported file type", path)
+		}
+	})
+}
+
+// copyFile copies the regular file src to dst with the given permissions
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+package core
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in its own process group and makes cancelling
+// its context kill the whole group, so a timeout also stops the processes
+// it started (e.g. the sleep of sh -c "sleep 30")
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// forwardSignals passes the signals that stop wt to the process group pid,
+// which no longer receives the terminal's Ctrl-C itself. The returned
+// function stops forwarding.
+func forwardSignals(pid int) func() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				_ = syscall.Kill(-pid, sig.(syscall.Signal))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
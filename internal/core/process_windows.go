@@ -0,0 +1,15 @@
+//go:build windows
+
+package core
+
+import "os/exec"
+
+// setProcessGroup is a no-op on Windows: cancelling the context kills the
+// command itself, and cmd.WaitDelay bounds the wait for its children
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// forwardSignals is a no-op on Windows, where the console delivers Ctrl-C
+// to the command directly
+func forwardSignals(pid int) func() {
+	return func() {}
+}
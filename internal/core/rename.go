@@ -0,0 +1,139 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/trungung/wt/internal/git"
+	"github.com/trungung/wt/internal/log"
+)
+
+// RenameWorktree renames the branch checked out in the worktree addressed by
+// target (see ResolveWorktree) to newBranch and moves the worktree directory
+// to the name MapBranchToDir gives newBranch, keeping its parent directory.
+// An upstream that tracked the remote branch of the old name is pointed at
+// the new name. If moving the directory fails, the branch is renamed back.
+// It returns the new path.
+func RenameWorktree(target, newBranch string, opts MoveOptions) (string, error) {
+	env, err := LoadRepoEnv()
+	if err != nil {
+		return "", err
+	}
+
+	wt, isMain, err := ResolveWorktree(target)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case isMain || wt.Branch == env.DefaultBranch:
+		return "", fmt.Errorf("refusing to rename default branch/main worktree")
+	case wt.Detached || wt.Bare:
+		return "", fmt.Errorf("worktree %s has no branch to rename", wt.Path)
+	case wt.Branch == newBranch:
+		return "", fmt.Errorf("worktree %s is already on branch %s", wt.Path, newBranch)
+	}
+	oldBranch := wt.Branch
+
+	newDir, err := MapBranchToDir(newBranch)
+	if err != nil {
+		return "", err
+	}
+	oldDir := filepath.Base(wt.Path)
+	newPath := filepath.Join(filepath.Dir(wt.Path), newDir)
+	move := evalPath(newPath) != evalPath(wt.Path)
+
+	unlock, err := lockDirs(env, oldDir, newDir)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	// Checked under the directory locks, so a concurrent wt for newBranch
+	// cannot create its worktree in between
+	if exists, _ := git.BranchExists(newBranch, env.Remote); exists {
+		return "", fmt.Errorf("branch %s already exists", newBranch)
+	}
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return "", err
+	}
+	if err := checkCollisions(newBranch, newDir, worktrees, oldBranch); err != nil {
+		return "", err
+	}
+	if move {
+		if err := checkMovable(wt, newPath, opts); err != nil {
+			return "", err
+		}
+	} else if wt.Locked && !opts.IgnoreLock {
+		return "", fmt.Errorf("worktree %s is %s; use --ignore-lock or wt unlock", oldBranch, DescribeLock(wt))
+	}
+
+	if err := withRepoLock(env, func() error {
+		return renameBranch(oldBranch, newBranch)
+	}); err != nil {
+		return "", err
+	}
+
+	if move {
+		if err := relocate(env, wt, newPath, opts); err != nil {
+			rbErr := withRepoLock(env, func() error {
+				return git.RenameBranch(newBranch, oldBranch)
+			})
+			status := "succeeded (branch renamed back)"
+			if rbErr != nil {
+				status = fmt.Sprintf("failed to rename branch back to %s: %v", oldBranch, rbErr)
+			}
+			return "", &RollbackError{
+				OriginalErr:    err,
+				RollbackErr:    rbErr,
+				RollbackStatus: status,
+			}
+		}
+		moveLogs(env.Root, oldDir, newDir)
+	}
+
+	return newPath, nil
+}
+
+// renameBranch renames a local branch. git moves its branch.<name>.* config
+// along, so an upstream tracking the remote branch of the old name keeps
+// doing so: pointing it at a remote branch that does not exist yet would
+// make the branch look [gone] to wt prune. The user is told how to publish
+// the new name instead.
+func renameBranch(oldBranch, newBranch string) error {
+	if err := git.RenameBranch(oldBranch, newBranch); err != nil {
+		return err
+	}
+	remote, err := git.GetConfig("branch." + newBranch + ".remote")
+	if err != nil || remote == "." {
+		return nil
+	}
+	if merge, err := git.GetConfig("branch." + newBranch + ".merge"); err == nil && merge == git.LocalBranchPrefix+oldBranch {
+		log.Warnf("%s still tracks %s/%s; publish the new name with: git push -u %s %s", newBranch, remote, oldBranch, remote, newBranch)
+	}
+	return nil
+}
+
+// moveLogs moves the logs kept for a worktree directory name along with a
+// renamed worktree. Failures only lose old logs, so they are not errors.
+func moveLogs(repoRoot, oldDir, newDir string) {
+	oldLogs, err := logDir(repoRoot, oldDir)
+	if err != nil {
+		return
+	}
+	newLogs, err := logDir(repoRoot, newDir)
+	if err != nil {
+		return
+	}
+	if _, err := os.Stat(oldLogs); err != nil {
+		return
+	}
+	if _, err := os.Stat(newLogs); err == nil {
+		log.Debugf("not moving logs %s: %s exists", oldLogs, newLogs)
+		return
+	}
+	if err := os.Rename(oldLogs, newLogs); err != nil {
+		log.Debugf("failed to move logs %s: %v", oldLogs, err)
+	}
+}
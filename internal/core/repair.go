@@ -0,0 +1,196 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/trungung/wt/internal/git"
+	"github.com/trungung/wt/internal/log"
+)
+
+// RepairFix is one change made by RepairWorktrees, or one it failed to make
+type RepairFix struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// RepairResult lists what RepairWorktrees changed and what it could not fix
+type RepairResult struct {
+	Repaired []RepairFix
+	Pruned   []RepairFix
+	Failed   []RepairFix
+}
+
+// RepairWorktrees reconnects worktrees whose links to the repository broke,
+// e.g. because the repository or the worktree base was moved or renamed. It
+// runs `git worktree repair` on every linked worktree directory (see
+// linkCandidates) and then prunes the records of worktrees whose directory
+// is gone. Locked worktrees are never pruned.
+func RepairWorktrees() (*RepairResult, error) {
+	env, err := LoadRepoEnv()
+	if err != nil {
+		return nil, err
+	}
+	commonDir, err := git.GetCommonDir(env.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RepairResult{}
+	err = withRepoLock(env, func() error {
+		candidates, err := linkCandidates(env, commonDir)
+		if err != nil {
+			return err
+		}
+		for _, dir := range candidates {
+			fixes, err := git.RepairWorktree(dir)
+			if err != nil {
+				result.Failed = append(result.Failed, RepairFix{Path: dir, Message: err.Error()})
+				continue
+			}
+			for _, fix := range fixes {
+				result.Repaired = append(result.Repaired, RepairFix{Path: dir, Message: fix})
+			}
+		}
+
+		// Records still prunable after the repair have no directory left
+		worktrees, err := git.ListWorktrees()
+		if err != nil {
+			return err
+		}
+		var prunable []git.Worktree
+		for _, wt := range worktrees {
+			if !wt.Prunable {
+				continue
+			}
+			if wt.Locked {
+				log.Infof("keeping %s: %s", wt.Path, DescribeLock(wt))
+				continue
+			}
+			prunable = append(prunable, wt)
+		}
+		if len(prunable) == 0 {
+			return nil
+		}
+		if err := git.PruneWorktreeRecords(); err != nil {
+			return err
+		}
+		for _, wt := range prunable {
+			result.Pruned = append(result.Pruned, RepairFix{Path: wt.Path, Message: wt.PrunableReason})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BrokenLinks returns the linked worktree directories (see linkCandidates)
+// whose .git file and git's record of the worktree do not point at each
+// other, mapped to the problem found. RepairWorktrees fixes them.
+func BrokenLinks(env *RepoEnv) (map[string]string, error) {
+	commonDir, err := git.GetCommonDir(env.Root)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := linkCandidates(env, commonDir)
+	if err != nil {
+		return nil, err
+	}
+	broken := make(map[string]string)
+	for _, dir := range candidates {
+		if problem, _ := checkLink(dir, commonDir); problem != "" {
+			broken[dir] = problem
+		}
+	}
+	return broken, nil
+}
+
+// linkCandidates returns the directories that should be linked worktrees of
+// this repository: the existing directories of registered worktrees, and the
+// directories directly below the worktree base whose .git file points into
+// this repository or to a location that no longer exists. Worktrees of other
+// repositories sharing the base are left out.
+func linkCandidates(env *RepoEnv, commonDir string) ([]string, error) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var dirs []string
+	addDir := func(dir string) {
+		if key := evalPath(dir); !seen[key] {
+			seen[key] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for i, wt := range worktrees {
+		if i == 0 || wt.Bare {
+			continue
+		}
+		if fi, err := os.Stat(wt.Path); err == nil && fi.IsDir() {
+			addDir(wt.Path)
+		}
+	}
+
+	base := env.Config.GetWorktreeBase(env.Root)
+	entries, err := os.ReadDir(base)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read worktree base %s: %w", base, err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(base, e.Name())
+		if _, ours := checkLink(dir, commonDir); ours {
+			addDir(dir)
+		}
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// checkLink checks the .git file of the worktree directory dir and the
+// record in commonDir it points to. It returns the problem found, or "" if
+// both point at each other, and whether dir belongs to this repository as
+// far as can be told. Directories without a .git file are not worktrees.
+func checkLink(dir, commonDir string) (problem string, ours bool) {
+	data, err := os.ReadFile(filepath.Join(dir, ".git"))
+	if err != nil {
+		return "", false // No .git file, or a .git directory
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "malformed .git file", true
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	if _, err := os.Stat(gitDir); err != nil {
+		return fmt.Sprintf(".git file points to %s, which does not exist", gitDir), true
+	}
+	if evalPath(filepath.Dir(gitDir)) != evalPath(filepath.Join(commonDir, "worktrees")) {
+		return "", false // A worktree of another repository
+	}
+
+	data, err = os.ReadFile(filepath.Join(gitDir, "gitdir"))
+	if err != nil {
+		return fmt.Sprintf("git's record %s has no gitdir file", gitDir), true
+	}
+	back := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(back) {
+		back = filepath.Join(gitDir, back)
+	}
+	if evalPath(filepath.Dir(back)) != evalPath(dir) {
+		return fmt.Sprintf("git's record points to %s", filepath.Dir(back)), true
+	}
+	return "", true
+}
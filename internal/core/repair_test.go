@@ -0,0 +1,91 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckLink(t *testing.T) {
+	base := t.TempDir()
+	commonDir := filepath.Join(base, "repo", ".git")
+	otherCommonDir := filepath.Join(base, "other", ".git")
+
+	// worktree creates a worktree directory whose .git file points to gitDir,
+	// and a record in gitDir pointing back to recordPath (if not empty)
+	worktree := func(name, gitFile, gitDir, recordPath string) string {
+		dir := filepath.Join(base, "repo.wt", name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if gitFile != "" {
+			if err := os.WriteFile(filepath.Join(dir, ".git"), []byte(gitFile), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if recordPath != "" {
+			if err := os.MkdirAll(gitDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(gitDir, "gitdir"), []byte(filepath.Join(recordPath, ".git")+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+	record := func(commonDir, name string) string {
+		return filepath.Join(commonDir, "worktrees", name)
+	}
+
+	tests := []struct {
+		name        string
+		dir         string
+		wantProblem string
+		wantOurs    bool
+	}{
+		{
+			name:     "linked",
+			dir:      worktree("ok", "gitdir: "+record(commonDir, "ok")+"\n", record(commonDir, "ok"), filepath.Join(base, "repo.wt", "ok")),
+			wantOurs: true,
+		},
+		{
+			name: "no .git file",
+			dir:  worktree("plain", "", "", ""),
+		},
+		{
+			name:        "malformed .git file",
+			dir:         worktree("malformed", "nonsense", "", ""),
+			wantProblem: "malformed",
+			wantOurs:    true,
+		},
+		{
+			name:        "repository moved",
+			dir:         worktree("moved-repo", "gitdir: /nowhere/.git/worktrees/moved-repo\n", "", ""),
+			wantProblem: "does not exist",
+			wantOurs:    true,
+		},
+		{
+			name:        "worktree moved",
+			dir:         worktree("moved-wt", "gitdir: "+record(commonDir, "old")+"\n", record(commonDir, "old"), filepath.Join(base, "repo.wt", "old")),
+			wantProblem: "git's record points to",
+			wantOurs:    true,
+		},
+		{
+			name: "other repository",
+			dir:  worktree("foreign", "gitdir: "+record(otherCommonDir, "foreign")+"\n", record(otherCommonDir, "foreign"), filepath.Join(base, "elsewhere")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem, ours := checkLink(tt.dir, commonDir)
+			if ours != tt.wantOurs {
+				t.Errorf("checkLink() ours = %v, want %v", ours, tt.wantOurs)
+			}
+			if tt.wantProblem == "" && problem != "" || !strings.Contains(problem, tt.wantProblem) {
+				t.Errorf("checkLink() problem = %q, want %q", problem, tt.wantProblem)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/trungung/wt/internal/git"
+)
+
+// errNoWorktree is returned by ResolveWorktree if target matches no worktree
+var errNoWorktree = errors.New("no worktree found")
+
+// minCommitPrefix is the shortest commit id prefix accepted to address a worktree
+const minCommitPrefix = 4
+
+// ResolveWorktree returns the worktree addressed by target and whether it is
+// the main worktree. target may be, in order of precedence:
+//
+//   - a branch name
+//   - a worktree directory name (e.g. feature-x)
+//   - a worktree path, absolute or relative to the current directory
+//   - a prefix of at least 4 characters of the commit checked out
+//
+// Detached and prunable worktrees have no usable branch name, so they are
+// addressed by the other forms. An error is returned if target matches
+// nothing or more than one worktree.
+func ResolveWorktree(target string) (git.Worktree, bool, error) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return git.Worktree{}, false, err
+	}
+	i, err := resolveWorktree(worktrees, target)
+	if err != nil {
+		return git.Worktree{}, false, err
+	}
+	return worktrees[i], i == 0, nil
+}
+
+// resolveWorktree returns the index of the worktree addressed by target.
+// See ResolveWorktree.
+func resolveWorktree(worktrees []git.Worktree, target string) (int, error) {
+	if target == "" {
+		return -1, fmt.Errorf("no worktree given")
+	}
+
+	matchers := []struct {
+		kind  string
+		match func(wt git.Worktree) bool
+	}{
+		{"branch", func(wt git.Worktree) bool { return wt.Branch == target }},
+		{"directory name", func(wt git.Worktree) bool { return filepath.Base(wt.Path) == target }},
+		{"path", pathMatcher(target)},
+		{"commit", commitMatcher(target)},
+	}
+
+	for _, m := range matchers {
+		if m.match == nil {
+			continue
+		}
+		var found []int
+		for i, wt := range worktrees {
+			if m.match(wt) {
+				found = append(found, i)
+			}
+		}
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		default:
+			var paths []string
+			for _, i := range found {
+				paths = append(paths, worktrees[i].Path)
+			}
+			return -1, fmt.Errorf("%s %q matches %d worktrees (%s); use a path instead",
+				m.kind, target, len(found), strings.Join(paths, ", "))
+		}
+	}
+
+	return -1, fmt.Errorf("%w for %s", errNoWorktree, target)
+}
+
+// pathMatcher matches worktrees located at target. Symlinks are resolved
+// where the paths exist, so prunable worktrees still match their path.
+func pathMatcher(target string) func(wt git.Worktree) bool {
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return nil
+	}
+	want := evalPath(abs)
+	return func(wt git.Worktree) bool {
+		return evalPath(wt.Path) == want
+	}
+}
+
+// evalPath cleans path and resolves symlinks if it exists
+func evalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// commitMatcher matches worktrees whose HEAD starts with target, if target
+// looks like an abbreviated commit id
+func commitMatcher(target string) func(wt git.Worktree) bool {
+	if len(target) < minCommitPrefix || len(target) > 40 {
+		return nil
+	}
+	prefix := strings.ToLower(target)
+	for _, c := range prefix {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return nil
+		}
+	}
+	return func(wt git.Worktree) bool {
+		return wt.Head != "" && strings.HasPrefix(wt.Head, prefix)
+	}
+}
+
+// worktreeLabel names a worktree in messages: its branch, or its path for
+// detached and bare worktrees
+func worktreeLabel(wt git.Worktree) string {
+	if wt.Detached || wt.Bare {
+		return wt.Path
+	}
+	return wt.Branch
+}
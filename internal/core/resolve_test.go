@@ -0,0 +1,84 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/trungung/wt/internal/git"
+)
+
+func TestResolveWorktree(t *testing.T) {
+	base := t.TempDir()
+	worktrees := []git.Worktree{
+		{Path: filepath.Join(base, "repo"), Branch: "main", Head: "aaaa1111"},
+		{Path: filepath.Join(base, "repo.wt", "feature-x"), Branch: "feature/x", Head: "bbbb2222"},
+		{Path: filepath.Join(base, "repo.wt", "detached-1"), Branch: git.DetachedBranchName, Detached: true, Head: "cccc3333"},
+		{Path: filepath.Join(base, "repo.wt", "detached-2"), Branch: git.DetachedBranchName, Detached: true, Head: "cccc4444"},
+		{Path: filepath.Join(base, "repo.wt", "gone"), Branch: "gone", Prunable: true, Head: "dddd5555"},
+	}
+	for _, wt := range worktrees[:4] {
+		if err := os.MkdirAll(wt.Path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Relative paths are resolved against the current directory
+	t.Chdir(filepath.Join(base, "repo.wt"))
+
+	tests := []struct {
+		name    string
+		target  string
+		want    int
+		wantErr string
+	}{
+		{name: "branch", target: "feature/x", want: 1},
+		{name: "directory name", target: "feature-x", want: 1},
+		{name: "absolute path", target: worktrees[2].Path, want: 2},
+		{name: "relative path", target: "./detached-2", want: 3},
+		{name: "missing directory", target: worktrees[4].Path, want: 4},
+		{name: "commit prefix", target: "cccc4", want: 3},
+		{name: "commit prefix is case-insensitive", target: "BBBB", want: 1},
+		{name: "ambiguous commit", target: "cccc", wantErr: "matches 2 worktrees"},
+		{name: "ambiguous placeholder", target: git.DetachedBranchName, wantErr: "matches 2 worktrees"},
+		{name: "too short commit", target: "ccc", wantErr: "no worktree found"},
+		{name: "unknown", target: "nope", wantErr: "no worktree found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveWorktree(worktrees, tt.target)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveWorktree(%q) error = %v, want %q", tt.target, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveWorktree(%q) unexpected error: %v", tt.target, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveWorktree(%q) = %d, want %d", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsExplicitPath(t *testing.T) {
+	for target, want := range map[string]bool{
+		"feature/x":    false,
+		"feature-x":    false,
+		"cafe":         false,
+		"(detached)":   false,
+		".":            true,
+		"..":           true,
+		"./feature-x":  true,
+		"../repo.wt/x": true,
+		"/tmp/x":       true,
+	} {
+		if got := isExplicitPath(target); got != want {
+			t.Errorf("isExplicitPath(%q) = %v, want %v", target, got, want)
+		}
+	}
+}
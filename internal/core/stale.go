@@ -0,0 +1,110 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trungung/wt/internal/git"
+)
+
+// Age rules of PruneWorktrees (see PruneRuleMerged for the others)
+const (
+	// PruneRuleOlderThan: the branch's last commit is older than
+	// PruneOptions.OlderThan
+	PruneRuleOlderThan = "older-than"
+	// PruneRuleInactive: neither a commit, a file change in the worktree nor
+	// wt returning the worktree happened within PruneOptions.InactiveFor
+	PruneRuleInactive = "inactive"
+)
+
+// ParseAge parses an age such as 14d, 2w or 36h: a whole number of days (d)
+// or weeks (w), or anything time.ParseDuration accepts. It must be positive.
+func ParseAge(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		d, err = parseDays(n, 1)
+	} else if n, ok := strings.CutSuffix(s, "w"); ok {
+		d, err = parseDays(n, 7)
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q: use e.g. 14d, 2w or 36h", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid age %q: must be positive", s)
+	}
+	return d, nil
+}
+
+func parseDays(n string, factor int) (time.Duration, error) {
+	days, err := strconv.Atoi(n)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(days*factor) * 24 * time.Hour, nil
+}
+
+// ageRule returns the age rule by which PruneWorktrees selects wt, or "" if
+// none matches, and fills in the timestamps it was judged by
+func ageRule(env *RepoEnv, wt git.Worktree, opts PruneOptions, now time.Time, entry *PruneEntry) (string, error) {
+	if opts.OlderThan <= 0 && opts.InactiveFor <= 0 {
+		return "", nil
+	}
+	lastCommit, err := git.GetCommitTime(wt.Branch)
+	if err != nil {
+		return "", err
+	}
+	if opts.OlderThan > 0 && now.Sub(lastCommit) > opts.OlderThan {
+		entry.LastCommit = lastCommit
+		return PruneRuleOlderThan, nil
+	}
+	if opts.InactiveFor <= 0 || now.Sub(lastCommit) <= opts.InactiveFor {
+		return "", nil
+	}
+
+	// The cheap checks first: a recent access spares walking the worktree
+	lastAccessed := lastAccess(env.Root, filepath.Base(wt.Path))
+	if now.Sub(lastAccessed) <= opts.InactiveFor {
+		return "", nil
+	}
+	var lastModified time.Time
+	if !wt.Prunable {
+		if lastModified, err = lastModification(wt.Path); err != nil {
+			return "", err
+		}
+		if now.Sub(lastModified) <= opts.InactiveFor {
+			return "", nil
+		}
+	}
+	entry.LastCommit = lastCommit
+	entry.LastModified = lastModified
+	entry.LastAccess = lastAccessed
+	return PruneRuleInactive, nil
+}
+
+// lastModification returns the latest modification time of the files in the
+// worktree at path that git does not ignore, so build output and dependency
+// directories such as node_modules do not count
+func lastModification(path string) (time.Time, error) {
+	files, err := git.ListFiles(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var latest time.Time
+	for _, f := range files {
+		fi, err := os.Lstat(filepath.Join(path, f))
+		if err != nil {
+			continue // Deleted, but still in the index
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
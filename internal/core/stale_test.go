@@ -0,0 +1,73 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "14d", want: 14 * 24 * time.Hour},
+		{input: "2w", want: 14 * 24 * time.Hour},
+		{input: "36h", want: 36 * time.Hour},
+		{input: "90m", want: 90 * time.Minute},
+		{input: "", wantErr: true},
+		{input: "d", wantErr: true},
+		{input: "1.5d", wantErr: true},
+		{input: "0d", wantErr: true},
+		{input: "-3d", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAge(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLastModification(t *testing.T) {
+	repo, runGit := initGitRepo(t, "main")
+	old := time.Now().Add(-30 * 24 * time.Hour).Truncate(time.Second)
+	recent := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	write := func(name, content string, mtime time.Time) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(".gitignore", "node_modules/\n", old)
+	write("tracked.txt", "tracked", old)
+	runGit("add", ".gitignore", "tracked.txt")
+	runGit("commit", "-q", "-m", "files")
+	write("untracked.txt", "untracked", recent)
+	// Ignored files, e.g. dependencies installed today, do not count
+	write("node_modules/dep/index.js", "dep", time.Now())
+
+	got, err := lastModification(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(recent) {
+		t.Errorf("lastModification() = %v, want %v (untracked.txt)", got, recent)
+	}
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trungung/wt/internal/git"
+	"github.com/trungung/wt/internal/log"
+)
+
+// statusWorkers bounds the number of worktrees inspected concurrently.
+const statusWorkers = 8
+
+// WorktreeStatus represents the detailed status of a worktree
+type WorktreeStatus struct {
+	Worktree   git.Worktree
+	IsMain     bool
+	Head       string
+	LastCommit time.Time
+	Changes    git.StatusCounts
+
+	// Upstream is empty when the branch has no upstream configured
+	Upstream       string
+	AheadUpstream  int
+	BehindUpstream int
+
+	// DefaultBranch is the branch AheadDefault/BehindDefault are relative to;
+	// empty when no comparison was made
+	DefaultBranch string
+	AheadDefault  int
+	BehindDefault int
+	Merged        bool
+
+	// Err is set when the worktree could not be inspected (e.g. its directory is missing)
+	Err error
+}
+
+// IsDirty returns true if the worktree has uncommitted changes
+func (s WorktreeStatus) IsDirty() bool {
+	return s.Changes.Total() > 0
+}
+
+// GetWorktreeStatuses returns detailed status for all worktrees.
+// Worktrees are inspected concurrently; the result keeps git's list order.
+func GetWorktreeStatuses() ([]WorktreeStatus, error) {
+	env, err := LoadRepoEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	mergedSet := make(map[string]bool)
+	if env.DefaultBranch != "" {
+		merged, err := git.GetMergedBranches(env.DefaultBranch)
+		if err != nil {
+			log.Warnf("failed to get merged branches: %v", err)
+		}
+		for _, b := range merged {
+			mergedSet[b] = true
+		}
+	}
+
+	statuses := make([]WorktreeStatus, len(worktrees))
+	sem := make(chan struct{}, statusWorkers)
+	var wg sync.WaitGroup
+	for i, wt := range worktrees {
+		wg.Add(1)
+		go func(i int, wt git.Worktree) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			s := collectStatus(wt, env.DefaultBranch)
+			s.IsMain = i == 0
+			s.Merged = wt.Branch != env.DefaultBranch && mergedSet[wt.Branch]
+			statuses[i] = s
+		}(i, wt)
+	}
+	wg.Wait()
+
+	return statuses, nil
+}
+
+// collectStatus gathers the status of a single worktree
+func collectStatus(wt git.Worktree, defaultBranch string) WorktreeStatus {
+	s := WorktreeStatus{Worktree: wt}
+
+	switch {
+	case wt.Bare:
+		s.Err = fmt.Errorf("bare repository")
+		return s
+	case wt.Prunable:
+		s.Err = fmt.Errorf("prunable: %s", wt.PrunableReason)
+		return s
+	}
+
+	changes, err := git.GetStatusCounts(wt.Path)
+	if err != nil {
+		s.Err = err
+		return s
+	}
+	s.Changes = changes
+
+	if head, date, err := git.GetLastCommit(wt.Path); err == nil {
+		s.Head = head
+		s.LastCommit = date
+	}
+
+	if !wt.Detached {
+		if upstream, err := git.GetUpstream(wt.Path); err == nil {
+			s.Upstream = upstream
+			s.AheadUpstream, s.BehindUpstream, _ = git.AheadBehind(wt.Path, "HEAD", upstream)
+		}
+	}
+
+	if defaultBranch != "" && wt.Branch != defaultBranch {
+		ahead, behind, err := git.AheadBehind(wt.Path, "HEAD", git.LocalBranchPrefix+defaultBranch)
+		if err != nil {
+			log.Debugf("skipping default branch comparison for %s: %v", wt.Branch, err)
+		} else {
+			s.DefaultBranch = defaultBranch
+			s.AheadDefault, s.BehindDefault = ahead, behind
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/trungung/wt/internal/config"
+	"github.com/trungung/wt/internal/trust"
+)
+
+var (
+	trustAll    bool
+	trustPrompt func(configPath string, commands []string) bool
+)
+
+// SetTrustAll treats every repository config as trusted for this process
+// without prompting or recording approval (used by --trust in CI).
+func SetTrustAll(v bool) {
+	trustAll = v
+}
+
+// SetTrustPrompt sets the function used to ask the user to approve the
+// commands of an untrusted config. It returns true if they were approved.
+func SetTrustPrompt(fn func(configPath string, commands []string) bool) {
+	trustPrompt = fn
+}
+
+// ConfigCommands returns every command defined in cfg, labelled with the
+// config key it comes from (e.g. "postCreateCmd: npm install").
+func ConfigCommands(cfg *config.Config) []string {
+	var out []string
+	for _, c := range cfg.PostCreateCmd {
+		out = append(out, "postCreateCmd: "+c.String())
+	}
+	for _, event := range config.HookEvents {
+		for _, c := range cfg.Hooks.Commands(event) {
+			out = append(out, "hooks."+event+": "+c.String())
+		}
+	}
+	return out
+}
+
+// IsConfigTrusted reports whether the commands in the repository config have
+// been approved. A config without commands is always trusted.
+func IsConfigTrusted(env *RepoEnv) (bool, error) {
+	if !env.Config.HasCommands() {
+		return true, nil
+	}
+	hash, err := trust.Hash(env.Config)
+	if err != nil {
+		return false, err
+	}
+	return trust.IsTrusted(config.GetConfigPath(env.Root), hash)
+}
+
+// TrustConfig records approval of the commands currently in the repository config
+func TrustConfig(env *RepoEnv) error {
+	hash, err := trust.Hash(env.Config)
+	if err != nil {
+		return err
+	}
+	return trust.Trust(config.GetConfigPath(env.Root), hash)
+}
+
+// UntrustConfig revokes approval of the repository config
+func UntrustConfig(env *RepoEnv) error {
+	return trust.Untrust(config.GetConfigPath(env.Root))
+}
+
+// checkTrust makes sure the repository's commands may run. Untrusted commands
+// are shown to the user for approval (recorded on success); without a prompt,
+// or if declined, the operation fails before anything is modified.
+func checkTrust(env *RepoEnv) error {
+	if trustAll {
+		return nil
+	}
+	trusted, err := IsConfigTrusted(env)
+	if err != nil {
+		return fmt.Errorf("failed to check trust: %w", err)
+	}
+	if trusted {
+		return nil
+	}
+
+	configPath := config.GetConfigPath(env.Root)
+	if trustPrompt != nil && trustPrompt(configPath, ConfigCommands(env.Config)) {
+		return TrustConfig(env)
+	}
+	return fmt.Errorf("commands in %s are not trusted; review them and run 'wt trust' (or pass --trust)", configPath)
+}
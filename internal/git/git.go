@@ -4,28 +4,51 @@ import (
 	"bytes"
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // Worktree represents a git worktree
 type Worktree struct {
-	Path   string
-	Branch string
+	Path   string `json:"path"`
+	Branch string `json:"branch"`
+	// Head is the full commit id checked out in the worktree
+	Head string `json:"head,omitempty"`
+	// Bare is set for the main entry of a bare repository; Branch is then
+	// BareBranchName
+	Bare bool `json:"bare"`
+	// Detached is set when HEAD is not a branch; Branch is then
+	// DetachedBranchName, so address such worktrees by path or commit
+	Detached bool `json:"detached"`
+	// Locked is set by `git worktree lock`; LockReason is optional
+	Locked     bool   `json:"locked"`
+	LockReason string `json:"lockReason,omitempty"`
+	// Prunable is set when git considers the worktree stale (e.g. its
+	// directory is gone); PrunableReason says why
+	Prunable       bool   `json:"prunable"`
+	PrunableReason string `json:"prunableReason,omitempty"`
 }
 
 // Constants for git references and special values
 const (
 	// DetachedBranchName is the placeholder for detached HEAD state
 	DetachedBranchName = "(detached)"
-	// OriginHeadRef is the git reference for the remote default branch
-	OriginHeadRef = "refs/remotes/origin/HEAD"
+	// BareBranchName is the placeholder for the entry of a bare repository
+	BareBranchName = "(bare)"
 	// LocalBranchPrefix is the prefix for local branch references
 	LocalBranchPrefix = "refs/heads/"
-	// RemoteBranchPrefix is the prefix for remote branch references
-	RemoteBranchPrefix = "refs/remotes/origin/"
+	// RemotePrefix is the prefix for remote-tracking references; the remote
+	// name and branch follow, e.g. refs/remotes/origin/main
+	RemotePrefix = "refs/remotes/"
 )
 
+// RemoteBranchRef returns the remote-tracking reference of a branch on remote
+func RemoteBranchRef(remote, branch string) string {
+	return RemotePrefix + remote + "/" + branch
+}
+
 // run executes a git command and returns the output
 func run(dir string, args ...string) ([]byte, error) {
 	start := time.Now()
@@ -67,44 +90,60 @@ func ListWorktrees() ([]Worktree, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
+	return parseWorktreeList(out), nil
+}
 
+// parseWorktreeList parses the output of `git worktree list --porcelain`
+func parseWorktreeList(out []byte) []Worktree {
 	var worktrees []Worktree
 	var current Worktree
 
-	lines := strings.Split(string(out), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "worktree ") {
-			if current.Path != "" {
-				// Flush previous worktree if it didn't have a branch (detached)
-				if current.Branch == "" {
-					current.Branch = DetachedBranchName
-				}
-				worktrees = append(worktrees, current)
-			}
-			current = Worktree{
-				Path: strings.TrimPrefix(line, "worktree "),
-			}
-		} else if strings.HasPrefix(line, "branch ") {
-			ref := strings.TrimPrefix(line, "branch ")
-			current.Branch = strings.TrimPrefix(ref, LocalBranchPrefix)
+	flush := func() {
+		if current.Path == "" {
+			return
 		}
-	}
-	// Final flush
-	if current.Path != "" {
+		// Worktrees without a branch line are detached
 		if current.Branch == "" {
 			current.Branch = DetachedBranchName
+			current.Detached = true
 		}
 		worktrees = append(worktrees, current)
 	}
 
-	return worktrees, nil
+	lines := strings.Split(string(out), "\n")
+	for _, line := range lines {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "worktree":
+			flush()
+			current = Worktree{Path: value}
+		case "HEAD":
+			current.Head = value
+		case "branch":
+			current.Branch = strings.TrimPrefix(value, LocalBranchPrefix)
+		case "bare":
+			current.Bare = true
+			current.Branch = BareBranchName
+		case "detached":
+			current.Detached = true
+		case "locked":
+			current.Locked = true
+			current.LockReason = value
+		case "prunable":
+			current.Prunable = true
+			current.PrunableReason = value
+		}
+	}
+	flush()
+
+	return worktrees
 }
 
-// FetchPrune runs git fetch --prune
-func FetchPrune() error {
-	_, stderr, err := runWithStderr("", "fetch", "--prune")
+// FetchPrune runs git fetch --prune for remote
+func FetchPrune(remote string) error {
+	_, stderr, err := runWithStderr("", "fetch", "--prune", remote)
 	if err != nil {
-		return fmt.Errorf("git fetch --prune failed: %s: %w", string(stderr), err)
+		return fmt.Errorf("git fetch --prune %s failed: %s: %w", remote, string(stderr), err)
 	}
 	return nil
 }
@@ -118,39 +157,236 @@ func GetMergedBranches(base string) ([]string, error) {
 	return parseLines(out), nil
 }
 
-// GetRepoRoot returns the absolute path to the git repository root
+// ListGoneBranches returns the local branches whose upstream branch was
+// deleted on the remote, shown as [gone] by git branch -vv once a fetch
+// with --prune removed the remote-tracking branch
+func ListGoneBranches() ([]string, error) {
+	out, err := run("", "for-each-ref", "--format=%(refname)\t%(upstream:track)", LocalBranchPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upstream tracking state: %w", err)
+	}
+	return parseGoneBranches(out), nil
+}
+
+// parseGoneBranches parses `<refname>\t<upstream:track>` lines and returns
+// the branches tracked as [gone]
+func parseGoneBranches(output []byte) []string {
+	var gone []string
+	for _, line := range parseLines(output) {
+		ref, track, _ := strings.Cut(line, "\t")
+		if track == "[gone]" {
+			gone = append(gone, strings.TrimPrefix(ref, LocalBranchPrefix))
+		}
+	}
+	return gone
+}
+
+// MergeBase returns the best common ancestor of a and b
+func MergeBase(a, b string) (string, error) {
+	out, err := run("", "merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("no merge base of %s and %s: %w", a, b, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetTree returns the id of the tree of commit rev
+func GetTree(rev string) (string, error) {
+	out, err := run("", "rev-parse", rev+"^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve tree of %s: %w", rev, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ListTrees returns the tree ids of the commits in revRange (e.g. a..b)
+func ListTrees(revRange string) ([]string, error) {
+	out, err := run("", "log", "--format=%T", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trees of %s: %w", revRange, err)
+	}
+	return parseLines(out), nil
+}
+
+// CountUnpicked returns how many commits head has that upstream does not, and
+// how many of those have no equivalent change (same patch id) in upstream,
+// as listed by `git cherry upstream head`
+func CountUnpicked(upstream, head string) (int, int, error) {
+	out, err := run("", "cherry", upstream, head)
+	if err != nil {
+		return 0, 0, fmt.Errorf("git cherry %s %s failed: %w", upstream, head, err)
+	}
+	lines := parseLines(out)
+	unpicked := 0
+	for _, line := range lines {
+		if strings.HasPrefix(line, "+") {
+			unpicked++
+		}
+	}
+	return len(lines), unpicked, nil
+}
+
+// DiffPatchID returns the stable patch id of the diff between from and to,
+// or "" if there is no difference
+func DiffPatchID(from, to string) (string, error) {
+	diff, err := run("", "diff", "--no-color", "--no-ext-diff", from, to)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s and %s: %w", from, to, err)
+	}
+	ids, err := patchIDs(diff)
+	if err != nil || len(ids) == 0 {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// ListPatchIDs returns the stable patch ids of the non-merge commits in
+// revRange (e.g. a..b); commits without changes have none
+func ListPatchIDs(revRange string) ([]string, error) {
+	patches, err := run("", "log", "-p", "--no-merges", "--no-color", "--no-ext-diff", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits of %s: %w", revRange, err)
+	}
+	return patchIDs(patches)
+}
+
+// patchIDs runs `git patch-id --stable` on patches and returns the patch ids
+func patchIDs(patches []byte) ([]string, error) {
+	if len(patches) == 0 {
+		return nil, nil
+	}
+	start := time.Now()
+	cmd := exec.Command("git", "patch-id", "--stable")
+	cmd.Stdin = bytes.NewReader(patches)
+	out, err := cmd.Output()
+	debugLog([]string{"patch-id", "--stable"}, time.Since(start))
+	if err != nil {
+		return nil, fmt.Errorf("git patch-id failed: %w", err)
+	}
+	var ids []string
+	for _, line := range parseLines(out) {
+		ids = append(ids, strings.Fields(line)[0])
+	}
+	return ids, nil
+}
+
+// GetRepoRoot returns the absolute path to the root of the main worktree.
+// It resolves the same path from the main worktree, any linked worktree or
+// any subdirectory of them, so config lookup, locking and the worktree base
+// do not depend on where wt is run from.
 func GetRepoRoot() (string, error) {
-	out, err := run("", "rev-parse", "--show-toplevel")
+	out, err := run("", "rev-parse", "--show-toplevel", "--git-dir", "--git-common-dir")
 	if err != nil {
 		return "", fmt.Errorf("failed to get repo root: %w", err)
 	}
-	return strings.TrimSpace(string(out)), nil
+	lines := parseLines(out)
+	if len(lines) != 3 {
+		return "", fmt.Errorf("failed to get repo root: unexpected rev-parse output %q", out)
+	}
+	toplevel := lines[0]
+	gitDir, err := filepath.Abs(lines[1])
+	if err != nil {
+		return "", err
+	}
+	commonDir, err := filepath.Abs(lines[2])
+	if err != nil {
+		return "", err
+	}
+	return resolveMainRoot(toplevel, gitDir, commonDir)
+}
+
+// resolveMainRoot derives the main worktree from the current worktree's top
+// level, its git dir and the common dir shared by all worktrees.
+func resolveMainRoot(toplevel, gitDir, commonDir string) (string, error) {
+	// Only the main worktree uses the common dir as its own git dir
+	if gitDir == commonDir {
+		return toplevel, nil
+	}
+	// Linked worktree of a repository with the usual <root>/.git layout
+	if filepath.Base(commonDir) == ".git" {
+		return filepath.Dir(commonDir), nil
+	}
+	// Anything else (e.g. submodules): use git's own notion of the main worktree
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		return "", err
+	}
+	if len(worktrees) == 0 {
+		return "", fmt.Errorf("failed to get repo root: no worktrees listed")
+	}
+	return filepath.Clean(worktrees[0].Path), nil
+}
+
+// GetCommonDir returns the absolute path of the git directory shared by all
+// worktrees of the repository containing path (the main worktree's .git)
+func GetCommonDir(path string) (string, error) {
+	out, err := run(path, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to get git common dir: %w", err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return filepath.Abs(dir)
 }
 
 // GetDefaultBranch returns the default branch name (e.g., main or master)
-func GetDefaultBranch() (string, error) {
-	// Only check remote default branch via origin/HEAD
-	out, err := run("", "symbolic-ref", OriginHeadRef)
+// recorded in <remote>/HEAD
+func GetDefaultBranch(remote string) (string, error) {
+	out, err := run("", "symbolic-ref", RemoteBranchRef(remote, "HEAD"))
 	if err == nil {
-		parts := strings.Split(strings.TrimSpace(string(out)), "/")
-		return parts[len(parts)-1], nil
+		branch, ok := strings.CutPrefix(strings.TrimSpace(string(out)), RemoteBranchRef(remote, ""))
+		if ok && branch != "" {
+			return branch, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not determine default branch via %s/HEAD", remote)
+}
+
+// UpdateRemoteHead sets <remote>/HEAD to the remote's default branch by
+// asking the remote (`git remote set-head <remote> --auto`)
+func UpdateRemoteHead(remote string) error {
+	_, stderr, err := runWithStderr("", "remote", "set-head", remote, "--auto")
+	if err != nil {
+		return fmt.Errorf("git remote set-head failed: %s: %w", strings.TrimSpace(string(stderr)), err)
 	}
+	return nil
+}
 
-	return "", fmt.Errorf("could not determine default branch via origin/HEAD")
+// ListRemotes returns the names of the configured remotes
+func ListRemotes() ([]string, error) {
+	out, err := run("", "remote")
+	if err != nil {
+		return nil, fmt.Errorf("git remote failed: %w", err)
+	}
+	return parseLines(out), nil
 }
 
-// BranchExists checks if a branch exists locally or on origin
-func BranchExists(branch string) (bool, bool) {
+// BranchExists checks if a branch exists locally or on remote
+func BranchExists(branch, remote string) (bool, bool) {
 	_, errLocal := run("", "show-ref", "--verify", "--quiet", LocalBranchPrefix+branch)
-	_, errRemote := run("", "show-ref", "--verify", "--quiet", RemoteBranchPrefix+branch)
+	_, errRemote := run("", "show-ref", "--verify", "--quiet", RemoteBranchRef(remote, branch))
 	return errLocal == nil, errRemote == nil
 }
 
+// CreateTrackingWorktree creates a worktree at path with a new local branch
+// that starts at and tracks branch on remote
+func CreateTrackingWorktree(path, branch, remote string) error {
+	_, stderr, err := runWithStderr("", "worktree", "add", "--track", "-b", branch, path, remote+"/"+branch)
+	if err != nil {
+		return fmt.Errorf("git worktree add failed: %s: %w", string(stderr), err)
+	}
+	return nil
+}
+
 // CreateWorktree creates a new worktree at the specified path for the given branch
 func CreateWorktree(path, branch, base string) error {
 	args := []string{"worktree", "add"}
 	if base != "" {
-		args = append(args, "-b", branch, path, base)
+		// A new branch never tracks its base, even a remote one
+		args = append(args, "--no-track", "-b", branch, path, base)
 	} else {
 		args = append(args, path, branch)
 	}
@@ -162,10 +398,15 @@ func CreateWorktree(path, branch, base string) error {
 	return nil
 }
 
-// RemoveWorktree removes a worktree at the specified path
-func RemoveWorktree(path string, force bool) error {
+// RemoveWorktree removes a worktree. force removes it even if it is dirty;
+// locked must be set to remove a worktree locked with `git worktree lock`.
+func RemoveWorktree(path string, force, locked bool) error {
 	args := []string{"worktree", "remove"}
-	if force {
+	if force || locked {
+		args = append(args, "--force")
+	}
+	if locked {
+		// git requires a second --force for locked worktrees
 		args = append(args, "--force")
 	}
 	args = append(args, path)
@@ -177,6 +418,76 @@ func RemoveWorktree(path string, force bool) error {
 	return nil
 }
 
+// MoveWorktree moves a worktree with `git worktree move`, which renames the
+// directory and so only works within one filesystem. locked must be set to
+// move a worktree locked with `git worktree lock`.
+func MoveWorktree(path, newPath string, locked bool) error {
+	args := []string{"worktree", "move"}
+	if locked {
+		args = append(args, "--force", "--force")
+	}
+	args = append(args, path, newPath)
+
+	_, stderr, err := runWithStderr("", args...)
+	if err != nil {
+		return fmt.Errorf("git worktree move failed: %s: %w", strings.TrimSpace(string(stderr)), err)
+	}
+	return nil
+}
+
+// RepairWorktree fixes the links between the worktree at path and git's
+// record of it, e.g. after either was moved by other means than
+// MoveWorktree. It returns git's description of each fix, which is empty if
+// nothing was broken.
+func RepairWorktree(path string) ([]string, error) {
+	_, stderr, err := runWithStderr("", "worktree", "repair", path)
+	if err != nil {
+		return nil, fmt.Errorf("git worktree repair failed: %s: %w", strings.TrimSpace(string(stderr)), err)
+	}
+	var fixes []string
+	for _, line := range strings.Split(string(stderr), "\n") {
+		if fix, ok := strings.CutPrefix(strings.TrimSpace(line), "repair: "); ok {
+			fixes = append(fixes, fix)
+		}
+	}
+	return fixes, nil
+}
+
+// PruneWorktreeRecords runs `git worktree prune`, which deletes the records
+// of worktrees whose directory is gone. Locked worktrees are kept.
+func PruneWorktreeRecords() error {
+	_, stderr, err := runWithStderr("", "worktree", "prune")
+	if err != nil {
+		return fmt.Errorf("git worktree prune failed: %s: %w", strings.TrimSpace(string(stderr)), err)
+	}
+	return nil
+}
+
+// LockWorktree locks a worktree with `git worktree lock`, which keeps git
+// from pruning it. reason is optional.
+func LockWorktree(path, reason string) error {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	args = append(args, path)
+
+	_, stderr, err := runWithStderr("", args...)
+	if err != nil {
+		return fmt.Errorf("git worktree lock failed: %s: %w", strings.TrimSpace(string(stderr)), err)
+	}
+	return nil
+}
+
+// UnlockWorktree removes the lock set by LockWorktree
+func UnlockWorktree(path string) error {
+	_, stderr, err := runWithStderr("", "worktree", "unlock", path)
+	if err != nil {
+		return fmt.Errorf("git worktree unlock failed: %s: %w", strings.TrimSpace(string(stderr)), err)
+	}
+	return nil
+}
+
 // DeleteBranch deletes a local branch
 func DeleteBranch(branch string) error {
 	_, stderr, err := runWithStderr("", "branch", "-D", branch)
@@ -186,15 +497,152 @@ func DeleteBranch(branch string) error {
 	return nil
 }
 
+// statusPorcelain returns the output of git status --porcelain in path
+func statusPorcelain(path string) ([]byte, error) {
+	out, err := run(path, "status", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("git status failed in %s: %w", path, err)
+	}
+	return out, nil
+}
+
 // IsDirty returns true if the worktree at the given path has uncommitted changes
 func IsDirty(path string) (bool, error) {
-	out, err := run(path, "status", "--porcelain")
+	out, err := statusPorcelain(path)
 	if err != nil {
-		return false, fmt.Errorf("git status failed in %s: %w", path, err)
+		return false, err
 	}
 	return len(strings.TrimSpace(string(out))) > 0, nil
 }
 
+// StatusCounts summarizes the uncommitted changes in a worktree
+type StatusCounts struct {
+	Staged    int `json:"staged"`
+	Modified  int `json:"modified"`
+	Untracked int `json:"untracked"`
+}
+
+// Total returns the number of changed files
+func (c StatusCounts) Total() int {
+	return c.Staged + c.Modified + c.Untracked
+}
+
+// GetStatusCounts returns the number of staged, modified and untracked files
+// in the worktree at the given path
+func GetStatusCounts(path string) (StatusCounts, error) {
+	out, err := statusPorcelain(path)
+	if err != nil {
+		return StatusCounts{}, err
+	}
+	return parseStatusCounts(out), nil
+}
+
+// parseStatusCounts counts entries in git status --porcelain output.
+// A file that is both staged and modified counts towards both.
+func parseStatusCounts(output []byte) StatusCounts {
+	var c StatusCounts
+	for _, line := range strings.Split(string(output), "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		x, y := line[0], line[1]
+		if x == '?' && y == '?' {
+			c.Untracked++
+			continue
+		}
+		if x != ' ' {
+			c.Staged++
+		}
+		if y != ' ' {
+			c.Modified++
+		}
+	}
+	return c
+}
+
+// GetUpstream returns the upstream of the branch checked out in path
+// (e.g. origin/feature-x), or an error if none is configured
+func GetUpstream(path string) (string, error) {
+	out, err := run(path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	if err != nil {
+		return "", fmt.Errorf("no upstream configured in %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// AheadBehind returns how many commits rev has that other does not (ahead)
+// and how many commits other has that rev does not (behind)
+func AheadBehind(path, rev, other string) (int, int, error) {
+	out, err := run(path, "rev-list", "--left-right", "--count", rev+"..."+other)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to compare %s with %s: %w", rev, other, err)
+	}
+	return parseAheadBehind(out)
+}
+
+// parseAheadBehind parses git rev-list --left-right --count output
+func parseAheadBehind(output []byte) (int, int, error) {
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", string(output))
+	}
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", string(output))
+	}
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", string(output))
+	}
+	return ahead, behind, nil
+}
+
+// GetLastCommit returns the full HEAD sha and committer date in path
+func GetLastCommit(path string) (string, time.Time, error) {
+	out, err := run(path, "log", "-1", "--format=%H %ct")
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("git log failed in %s: %w", path, err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return "", time.Time{}, fmt.Errorf("unexpected git log output: %q", string(out))
+	}
+	ts, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unexpected git log output: %q", string(out))
+	}
+	return fields[0], time.Unix(ts, 0), nil
+}
+
+// GetCommitTime returns the committer date of rev
+func GetCommitTime(rev string) (time.Time, error) {
+	out, err := run("", "log", "-1", "--format=%ct", rev, "--")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log %s failed: %w", rev, err)
+	}
+	ts, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected git log output: %q", string(out))
+	}
+	return time.Unix(ts, 0), nil
+}
+
+// ListFiles returns the files of the worktree at path that git does not
+// ignore, tracked or not, relative to path
+func ListFiles(path string) ([]string, error) {
+	out, err := run(path, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed in %s: %w", path, err)
+	}
+	var files []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
 // GetCurrentBranchInMainWorktree returns the branch currently checked out in the main repo
 func GetCurrentBranchInMainWorktree(root string) (string, error) {
 	out, err := run(root, "branch", "--show-current")
@@ -204,6 +652,25 @@ func GetCurrentBranchInMainWorktree(root string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// RenameBranch renames a local branch with `git branch -m`. git updates the
+// worktree that has it checked out and moves its branch.<name>.* config.
+func RenameBranch(oldName, newName string) error {
+	_, stderr, err := runWithStderr("", "branch", "-m", oldName, newName)
+	if err != nil {
+		return fmt.Errorf("git branch -m failed: %s: %w", strings.TrimSpace(string(stderr)), err)
+	}
+	return nil
+}
+
+// GetConfig returns the value of a git config key, or an error if it is not set
+func GetConfig(key string) (string, error) {
+	out, err := run("", "config", "--get", key)
+	if err != nil {
+		return "", fmt.Errorf("git config %s is not set: %w", key, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // ListLocalBranches returns a list of all local branch names
 func ListLocalBranches() ([]string, error) {
 	out, err := run("", "branch", "--format=%(refname:short)")
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -62,3 +63,162 @@ func TestParseLines(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStatusCounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected StatusCounts
+	}{
+		{
+			name:     "clean",
+			input:    "",
+			expected: StatusCounts{},
+		},
+		{
+			name:     "untracked only",
+			input:    "?? a.txt\n?? b.txt\n",
+			expected: StatusCounts{Untracked: 2},
+		},
+		{
+			name:     "staged and modified",
+			input:    "M  staged.go\n M modified.go\nMM both.go\nA  added.go\n",
+			expected: StatusCounts{Staged: 3, Modified: 2},
+		},
+		{
+			name:     "deleted and renamed",
+			input:    " D gone.go\nR  old.go -> new.go\n",
+			expected: StatusCounts{Staged: 1, Modified: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseStatusCounts([]byte(tt.input))
+			if got != tt.expected {
+				t.Errorf("parseStatusCounts(%q) = %+v, want %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseAheadBehind(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantAhead  int
+		wantBehind int
+		wantErr    bool
+	}{
+		{name: "even", input: "0\t0\n", wantAhead: 0, wantBehind: 0},
+		{name: "ahead and behind", input: "3\t12\n", wantAhead: 3, wantBehind: 12},
+		{name: "empty", input: "", wantErr: true},
+		{name: "garbage", input: "x\ty\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ahead, behind, err := parseAheadBehind([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAheadBehind(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if ahead != tt.wantAhead || behind != tt.wantBehind {
+				t.Errorf("parseAheadBehind(%q) = %d, %d, want %d, %d", tt.input, ahead, behind, tt.wantAhead, tt.wantBehind)
+			}
+		})
+	}
+}
+
+func TestParseGoneBranches(t *testing.T) {
+	input := "refs/heads/main\t\n" +
+		"refs/heads/feature/done\t[gone]\n" +
+		"refs/heads/feature/wip\t[ahead 2]\n" +
+		"refs/heads/old\t[gone]\n"
+	got := parseGoneBranches([]byte(input))
+	want := []string{"feature/done", "old"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGoneBranches() = %v, want %v", got, want)
+	}
+	if got := parseGoneBranches(nil); got != nil {
+		t.Errorf("parseGoneBranches(nil) = %v, want nil", got)
+	}
+}
+
+func TestResolveMainRoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		toplevel  string
+		gitDir    string
+		commonDir string
+		expected  string
+	}{
+		{
+			name:      "main worktree",
+			toplevel:  "/src/repo",
+			gitDir:    "/src/repo/.git",
+			commonDir: "/src/repo/.git",
+			expected:  "/src/repo",
+		},
+		{
+			name:      "main worktree with separate git dir",
+			toplevel:  "/src/repo",
+			gitDir:    "/var/git/repo.git",
+			commonDir: "/var/git/repo.git",
+			expected:  "/src/repo",
+		},
+		{
+			name:      "linked worktree",
+			toplevel:  "/src/repo.wt/feature-x",
+			gitDir:    "/src/repo/.git/worktrees/feature-x",
+			commonDir: "/src/repo/.git",
+			expected:  "/src/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveMainRoot(filepath.FromSlash(tt.toplevel), filepath.FromSlash(tt.gitDir), filepath.FromSlash(tt.commonDir))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != filepath.FromSlash(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseWorktreeList(t *testing.T) {
+	input := `worktree /src/repo.git
+bare
+
+worktree /src/repo
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /src/repo.wt/feature-x
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/feature/x
+locked on usb drive
+
+worktree /src/repo.wt/gone
+HEAD 3333333333333333333333333333333333333333
+detached
+locked
+prunable gitdir file points to non-existent location
+
+`
+	expected := []Worktree{
+		{Path: "/src/repo.git", Branch: BareBranchName, Bare: true},
+		{Path: "/src/repo", Branch: "main", Head: "1111111111111111111111111111111111111111"},
+		{Path: "/src/repo.wt/feature-x", Branch: "feature/x", Head: "2222222222222222222222222222222222222222",
+			Locked: true, LockReason: "on usb drive"},
+		{Path: "/src/repo.wt/gone", Branch: DetachedBranchName, Head: "3333333333333333333333333333333333333333",
+			Detached: true, Locked: true, Prunable: true, PrunableReason: "gitdir file points to non-existent location"},
+	}
+
+	got := parseWorktreeList([]byte(input))
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseWorktreeList() = %+v, want %+v", got, expected)
+	}
+}
@@ -2,30 +2,304 @@ package git
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gofrs/flock"
 )
 
-// AcquireLock attempts to acquire a file lock for the repository.
-// It returns a function that must be called to release the lock.
+// LockFileName is the name of the repository lock in the git common dir
+const LockFileName = "wt.lock"
+
+// releaseGrace is how long a waiter that finds no live holder keeps trying
+// before giving up, covering a holder between removing its info and unlocking
+const releaseGrace = 200 * time.Millisecond
+
+// dirLocksDir is the directory below the git common dir holding the
+// per-worktree-directory locks
+const dirLocksDir = "wt/locks"
+
+// LockInfo describes the process holding a lock. It is stored next to the
+// lock file while the lock is held.
+type LockInfo struct {
+	PID      int       `json:"pid"`
+	Hostname string    `json:"hostname"`
+	Command  string    `json:"command"`
+	Started  time.Time `json:"started"`
+}
+
+// IsStale returns true if the recorded holder no longer exists. Processes on
+// other hosts cannot be checked and are never considered stale.
+func (i *LockInfo) IsStale() bool {
+	host, err := os.Hostname()
+	if err != nil || host != i.Hostname {
+		return false
+	}
+	return !processExists(i.PID)
+}
+
+// String describes the holder, e.g. "PID 123 on host (wt feature/x), started 3s ago"
+func (i *LockInfo) String() string {
+	return fmt.Sprintf("PID %d on %s (%s), started %s ago",
+		i.PID, i.Hostname, i.Command, time.Since(i.Started).Round(time.Second))
+}
+
+// LockPath returns the path of the repository lock: <git-common-dir>/wt.lock.
+// All worktrees of a repository share it.
+func LockPath(repoRoot string) (string, error) {
+	commonDir, err := GetCommonDir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, LockFileName), nil
+}
+
+// DirLockPath returns the path of the lock for one worktree directory:
+// <git-common-dir>/wt/locks/<dir-name>.lock
+func DirLockPath(repoRoot, dirName string) (string, error) {
+	commonDir, err := GetCommonDir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, filepath.FromSlash(dirLocksDir), dirName+".lock"), nil
+}
+
+// lockInfoPath returns the sidecar file holding the LockInfo for lockPath
+func lockInfoPath(lockPath string) string {
+	return lockPath + ".info"
+}
+
+// ReadLockInfo returns the holder information recorded for lockPath,
+// or nil if there is none.
+func ReadLockInfo(lockPath string) (*LockInfo, error) {
+	data, err := os.ReadFile(lockInfoPath(lockPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("invalid lock info %s: %w", lockInfoPath(lockPath), err)
+	}
+	return &info, nil
+}
+
+// writeLockInfo records the current process as the holder of lockPath
+func writeLockInfo(lockPath string) error {
+	host, _ := os.Hostname()
+	data, err := json.Marshal(LockInfo{
+		PID:      os.Getpid(),
+		Hostname: host,
+		Command:  strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " "),
+		Started:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Atomic write: temp file + rename
+	infoPath := lockInfoPath(lockPath)
+	tempFile := infoPath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFile, infoPath); err != nil {
+		_ = os.Remove(tempFile)
+		return err
+	}
+	return nil
+}
+
+// LockStatus describes the state of a lock for inspection
+type LockStatus struct {
+	Path string
+	// Name is "repository" for the repository lock, otherwise the worktree
+	// directory name the lock protects
+	Name string
+	// Held is true if another process currently holds the lock
+	Held bool
+	// Info is the recorded holder, if any. If the lock is not held, it was
+	// left behind by a process that exited without releasing it.
+	Info *LockInfo
+}
+
+// getLockStatus inspects a lock without waiting for it
+func getLockStatus(name, lockPath string) (LockStatus, error) {
+	status := LockStatus{Name: name, Path: lockPath}
+
+	if _, err := os.Stat(lockPath); err == nil {
+		fileLock := flock.New(lockPath)
+		locked, err := fileLock.TryLock()
+		if err != nil {
+			return status, fmt.Errorf("failed to inspect lock %s: %w", lockPath, err)
+		}
+		if locked {
+			_ = fileLock.Unlock()
+		}
+		status.Held = !locked
+	}
+
+	info, err := ReadLockInfo(lockPath)
+	if err != nil {
+		return status, err
+	}
+	status.Info = info
+	return status, nil
+}
+
+// ListLocks returns the repository lock followed by every worktree directory
+// lock that is held or has owner info left behind. Free directory locks
+// without info are omitted.
+func ListLocks(repoRoot string) ([]LockStatus, error) {
+	lockPath, err := LockPath(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	repoStatus, err := getLockStatus("repository", lockPath)
+	if err != nil {
+		return nil, err
+	}
+	statuses := []LockStatus{repoStatus}
+
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(lockPath), filepath.FromSlash(dirLocksDir), "*.lock"))
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		status, err := getLockStatus(strings.TrimSuffix(filepath.Base(m), ".lock"), m)
+		if err != nil {
+			return nil, err
+		}
+		if status.Held || status.Info != nil {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+// AcquireLock acquires the repository lock, which serializes changes to git
+// metadata (adding and removing worktrees, deleting branches). Hold it only
+// for those short steps. It returns a function that must be called to
+// release the lock.
 func AcquireLock(repoRoot string, timeout time.Duration) (func() error, error) {
-	lockPath := filepath.Join(repoRoot, ".git", "wt.lock")
-	fileLock := flock.New(lockPath)
+	lockPath, err := LockPath(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	return acquire(lockPath, timeout, nil, false)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+// AcquireDirLock acquires the lock for one worktree directory, which covers
+// the whole creation (including setup commands) or removal of that worktree.
+// Different directories can be locked concurrently. A timeout <= 0 waits
+// until the lock is free. If the lock is busy, waiting is called once with
+// the recorded holder (which may be nil) before waiting.
+func AcquireDirLock(repoRoot, dirName string, timeout time.Duration, waiting func(holder *LockInfo)) (func() error, error) {
+	return acquireDirLock(repoRoot, dirName, timeout, waiting, false)
+}
 
-	locked, err := fileLock.TryLockContext(ctx, 100*time.Millisecond)
+// AwaitDirLock is AcquireDirLock for waits that may take as long as the
+// holder's work, such as setup commands of a creation: it keeps waiting
+// while the recorded holder is alive and gives up after timeout only if the
+// lock is held by an unknown or vanished process.
+func AwaitDirLock(repoRoot, dirName string, timeout time.Duration, waiting func(holder *LockInfo)) (func() error, error) {
+	return acquireDirLock(repoRoot, dirName, timeout, waiting, true)
+}
+
+func acquireDirLock(repoRoot, dirName string, timeout time.Duration, waiting func(holder *LockInfo), whileAlive bool) (func() error, error) {
+	lockPath, err := DirLockPath(repoRoot, dirName)
 	if err != nil {
-		return nil, fmt.Errorf("another wt operation is in progress (failed to acquire lock at %s: %v)", lockPath, err)
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
 	}
+	return acquire(lockPath, timeout, waiting, whileAlive)
+}
+
+// acquire locks lockPath, waiting up to timeout (forever if timeout <= 0, and
+// for another timeout each time it expires while the recorded holder is alive
+// if whileAlive is set), and records the current process as its holder.
+func acquire(lockPath string, timeout time.Duration, waiting func(holder *LockInfo), whileAlive bool) (func() error, error) {
+	fileLock := flock.New(lockPath)
 
+	locked, err := fileLock.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock at %s: %w", lockPath, err)
+	}
 	if !locked {
-		return nil, fmt.Errorf("another wt operation is in progress")
+		if waiting != nil {
+			info, _ := ReadLockInfo(lockPath)
+			waiting(info)
+		}
+
+		for {
+			if locked, err = tryLockFor(fileLock, timeout); err != nil {
+				return nil, fmt.Errorf("failed to acquire lock at %s: %w", lockPath, err)
+			}
+			if locked || !whileAlive {
+				break
+			}
+			if info, _ := ReadLockInfo(lockPath); info == nil || info.IsStale() {
+				// A holder removes its info just before unlocking
+				if locked, err = tryLockFor(fileLock, releaseGrace); err != nil {
+					return nil, fmt.Errorf("failed to acquire lock at %s: %w", lockPath, err)
+				}
+				break
+			}
+		}
+		if !locked {
+			return nil, lockBusyError(lockPath, timeout)
+		}
 	}
 
-	return fileLock.Unlock, nil
+	// Any info still present was left by a holder that exited without
+	// releasing the lock (e.g. killed); it is simply replaced.
+	if err := writeLockInfo(lockPath); err != nil {
+		_ = fileLock.Unlock()
+		return nil, fmt.Errorf("failed to record lock owner: %w", err)
+	}
+
+	// The lock file itself is kept: removing it would let a new process lock
+	// a fresh file while another still waits on the old one.
+	return func() error {
+		_ = os.Remove(lockInfoPath(lockPath))
+		return fileLock.Unlock()
+	}, nil
+}
+
+// tryLockFor tries to lock fileLock for up to timeout (forever if timeout <= 0)
+func tryLockFor(fileLock *flock.Flock, timeout time.Duration) (bool, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	locked, err := fileLock.TryLockContext(ctx, 100*time.Millisecond)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return false, err
+	}
+	return locked, nil
+}
+
+// lockBusyError describes who holds the lock after waiting for timeout
+func lockBusyError(lockPath string, timeout time.Duration) error {
+	info, _ := ReadLockInfo(lockPath)
+	switch {
+	case info == nil:
+		return fmt.Errorf("another wt operation is in progress (waited %s for %s)", timeout, lockPath)
+	case info.IsStale():
+		return fmt.Errorf("another wt operation is in progress (waited %s for %s; recorded owner PID %d no longer exists, the lock is held by an unknown process)",
+			timeout, lockPath, info.PID)
+	default:
+		return fmt.Errorf("another wt operation is in progress: held by %s (waited %s for %s)", info, timeout, lockPath)
+	}
 }
@@ -0,0 +1,176 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %s: %v", out, err)
+	}
+	return dir
+}
+
+func TestAcquireLock(t *testing.T) {
+	repo := initRepo(t)
+
+	unlock, err := AcquireLock(repo, time.Second)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+
+	statuses, err := ListLocks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := statuses[0]
+	if !status.Held {
+		t.Errorf("expected lock to be held")
+	}
+	if status.Info == nil || status.Info.PID != os.Getpid() {
+		t.Errorf("expected lock info for this process, got %+v", status.Info)
+	}
+
+	// A second acquisition times out and names the holder
+	_, err = AcquireLock(repo, 200*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected second AcquireLock to fail")
+	}
+	if !strings.Contains(err.Error(), "held by PID") {
+		t.Errorf("expected holder in error, got: %v", err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock failed: %v", err)
+	}
+	statuses, err = ListLocks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := statuses[0]; status.Held || status.Info != nil {
+		t.Errorf("expected free lock without info after unlock, got %+v", status)
+	}
+}
+
+func TestAcquireDirLock(t *testing.T) {
+	repo := initRepo(t)
+
+	// Different directories and the repository lock are independent
+	unlockA, err := AcquireDirLock(repo, "feature-a", time.Second, nil)
+	if err != nil {
+		t.Fatalf("AcquireDirLock(feature-a) failed: %v", err)
+	}
+	unlockB, err := AcquireDirLock(repo, "feature-b", time.Second, nil)
+	if err != nil {
+		t.Fatalf("AcquireDirLock(feature-b) failed: %v", err)
+	}
+	unlockRepo, err := AcquireLock(repo, time.Second)
+	if err != nil {
+		t.Fatalf("AcquireLock failed while directory locks are held: %v", err)
+	}
+	_ = unlockRepo()
+
+	statuses, err := ListLocks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range statuses {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "repository,feature-a,feature-b" {
+		t.Errorf("expected repository and both directory locks, got %v", names)
+	}
+
+	// The same directory is exclusive; waiting is reported with the holder
+	var holder *LockInfo
+	_, err = AcquireDirLock(repo, "feature-a", 200*time.Millisecond, func(h *LockInfo) {
+		holder = h
+	})
+	if err == nil {
+		t.Fatal("expected second AcquireDirLock(feature-a) to fail")
+	}
+	if holder == nil || holder.PID != os.Getpid() {
+		t.Errorf("expected waiting callback with this process as holder, got %+v", holder)
+	}
+
+	_ = unlockA()
+	_ = unlockB()
+
+	// Released locks without info are not listed
+	statuses, err = ListLocks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 1 {
+		t.Errorf("expected only the repository lock after release, got %+v", statuses)
+	}
+}
+
+func TestAwaitDirLock(t *testing.T) {
+	repo := initRepo(t)
+
+	// A live holder is waited for beyond the timeout
+	unlock, err := AcquireDirLock(repo, "feature-a", time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(600 * time.Millisecond)
+		_ = unlock()
+	}()
+	unlock, err = AwaitDirLock(repo, "feature-a", 200*time.Millisecond, nil)
+	if err != nil {
+		t.Fatalf("expected AwaitDirLock to wait for the live holder, got: %v", err)
+	}
+
+	// A holder recorded as gone is only waited for up to the timeout
+	lockPath, err := DirLockPath(repo, "feature-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+	cmd := exec.Command("go", "version")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot start helper process: %v", err)
+	}
+	data := fmt.Sprintf(`{"pid": %d, "hostname": %q}`, cmd.Process.Pid, host)
+	if err := os.WriteFile(lockInfoPath(lockPath), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AwaitDirLock(repo, "feature-a", 200*time.Millisecond, nil); err == nil {
+		t.Error("expected AwaitDirLock to give up on a lock whose holder is gone")
+	}
+	_ = unlock()
+}
+
+func TestLockInfo_IsStale(t *testing.T) {
+	host, _ := os.Hostname()
+
+	alive := &LockInfo{PID: os.Getpid(), Hostname: host}
+	if alive.IsStale() {
+		t.Errorf("expected running process not to be stale")
+	}
+
+	// Start and reap a process to get a PID that no longer exists
+	cmd := exec.Command("go", "version")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot start helper process: %v", err)
+	}
+	dead := &LockInfo{PID: cmd.Process.Pid, Hostname: host}
+	if !dead.IsStale() {
+		t.Errorf("expected exited process to be stale")
+	}
+
+	remote := &LockInfo{PID: cmd.Process.Pid, Hostname: host + "-other"}
+	if remote.IsStale() {
+		t.Errorf("expected holders on other hosts never to be stale")
+	}
+}
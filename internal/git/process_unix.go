@@ -0,0 +1,17 @@
+//go:build !windows
+
+package git
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processExists returns true if a process with the given PID is running
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
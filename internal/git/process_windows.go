@@ -0,0 +1,19 @@
+//go:build windows
+
+package git
+
+import "os"
+
+// processExists returns true if a process with the given PID is running
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	// On Windows FindProcess fails if the process does not exist
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
@@ -0,0 +1,104 @@
+// Package trust records which repository configs the user has approved to
+// run commands, direnv-style: approval is tied to the config's path and a hash
+// of its commands, so any change to the commands requires approval again.
+package trust
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/trungung/wt/internal/config"
+)
+
+// StateDir returns wt's per-user state directory
+// ($XDG_STATE_HOME/wt, defaulting to ~/.local/state/wt).
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "wt"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine state directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "wt"), nil
+}
+
+// Hash returns a content hash of the commands defined in cfg.
+// Changes to other config keys do not affect the hash.
+func Hash(cfg *config.Config) (string, error) {
+	data, err := json.Marshal(struct {
+		PostCreateCmd []config.Command `json:"postCreateCmd"`
+		Hooks         config.Hooks     `json:"hooks"`
+	}{cfg.PostCreateCmd, cfg.Hooks})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// recordPath returns the file that stores the approved hash for configPath
+func recordPath(configPath string) (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(configPath))
+	return filepath.Join(dir, "trust", hex.EncodeToString(sum[:])), nil
+}
+
+// IsTrusted returns true if configPath was approved with exactly this hash
+func IsTrusted(configPath, hash string) (bool, error) {
+	p, err := recordPath(configPath)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	lines := strings.SplitN(string(data), "\n", 2)
+	return strings.TrimSpace(lines[0]) == hash, nil
+}
+
+// Trust records approval of configPath with the given hash
+func Trust(configPath, hash string) error {
+	p, err := recordPath(configPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return fmt.Errorf("failed to create trust directory: %w", err)
+	}
+
+	// Atomic write: temp file + rename
+	tempFile := p + ".tmp"
+	if err := os.WriteFile(tempFile, []byte(hash+"\n"+configPath+"\n"), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFile, p); err != nil {
+		_ = os.Remove(tempFile)
+		return err
+	}
+	return nil
+}
+
+// Untrust removes any approval recorded for configPath
+func Untrust(configPath string) error {
+	p, err := recordPath(configPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
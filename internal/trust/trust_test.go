@@ -0,0 +1,68 @@
+package trust
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/trungung/wt/internal/config"
+)
+
+func TestHash(t *testing.T) {
+	base := &config.Config{PostCreateCmd: config.Commands("npm install")}
+	h1, err := Hash(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Non-command keys do not affect the hash
+	h2, _ := Hash(&config.Config{PostCreateCmd: config.Commands("npm install"), DefaultBranch: "develop"})
+	if h1 != h2 {
+		t.Errorf("expected hash to ignore non-command keys")
+	}
+
+	// Any command change does
+	h3, _ := Hash(&config.Config{PostCreateCmd: config.Commands("npm ci")})
+	if h1 == h3 {
+		t.Errorf("expected hash to change when postCreateCmd changes")
+	}
+	h4, _ := Hash(&config.Config{
+		PostCreateCmd: config.Commands("npm install"),
+		Hooks:         config.Hooks{PreRemove: config.Commands("docker compose down")},
+	})
+	if h1 == h4 {
+		t.Errorf("expected hash to change when hooks change")
+	}
+}
+
+func TestTrustLifecycle(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	configPath := filepath.Join(t.TempDir(), ".wt.config.json")
+	otherPath := filepath.Join(t.TempDir(), ".wt.config.json")
+
+	if ok, err := IsTrusted(configPath, "abc"); err != nil || ok {
+		t.Fatalf("expected unknown config to be untrusted, got %v, %v", ok, err)
+	}
+
+	if err := Trust(configPath, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := IsTrusted(configPath, "abc"); !ok {
+		t.Errorf("expected config to be trusted after Trust")
+	}
+	if ok, _ := IsTrusted(configPath, "def"); ok {
+		t.Errorf("expected a different hash to be untrusted")
+	}
+	if ok, _ := IsTrusted(otherPath, "abc"); ok {
+		t.Errorf("expected approval to be tied to the config path")
+	}
+
+	if err := Untrust(configPath); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := IsTrusted(configPath, "abc"); ok {
+		t.Errorf("expected config to be untrusted after Untrust")
+	}
+	if err := Untrust(configPath); err != nil {
+		t.Errorf("expected Untrust of an unknown config to succeed, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/charmbracelet/huh"
 )
@@ -20,3 +21,20 @@ func PromptBoolWithError(label string, defaultVal bool) (bool, error) {
 	}
 	return result, nil
 }
+
+// PromptBoolStderr is like PromptBoolWithError but renders on stderr, so it
+// can be used by commands whose stdout is captured (e.g. `wt cd`).
+func PromptBoolStderr(label string, defaultVal bool) (bool, error) {
+	result := defaultVal
+	err := huh.NewForm(huh.NewGroup(
+		huh.NewConfirm().
+			Title(label).
+			Affirmative("Yes").
+			Negative("No").
+			Value(&result),
+	)).WithOutput(os.Stderr).Run()
+	if err != nil {
+		return false, fmt.Errorf("prompt failed: %w", err)
+	}
+	return result, nil
+}
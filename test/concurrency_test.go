@@ -1,12 +1,16 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/trungung/wt/internal/git"
 )
 
 func TestConcurrency(t *testing.T) {
@@ -26,7 +30,6 @@ func TestConcurrency(t *testing.T) {
 	}
 
 	// Init git repo
-	exec.Command("git", "init", "-b", "main").Dir = repoPath
 	runCmd(repoPath, "git", "init", "-b", "main")
 	runCmd(repoPath, "git", "config", "user.email", "test@example.com")
 	runCmd(repoPath, "git", "config", "user.name", "test")
@@ -46,43 +49,212 @@ func TestConcurrency(t *testing.T) {
 		t.Fatalf("failed to build binary: %s: %v", string(out), err)
 	}
 
-	// 1. Setup a slow postCreateCmd
-	configContent := `{
-		"defaultBranch": "main",
-		"postCreateCmd": ["sleep 10"]
-	}`
-	if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(configContent), 0644); err != nil {
-		t.Fatal(err)
+	writeConfig := func(content string) {
+		if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
 	}
 
-	// 2. Start first wt command in background
-	cmd1 := exec.Command(binPath, "feature/slow")
-	cmd1.Dir = repoPath
-	errChan := make(chan error, 1)
-	go func() {
-		errChan <- cmd1.Run()
-	}()
+	type result struct {
+		stdout string
+		stderr string
+		err    error
+	}
+	// runConcurrently starts one wt process per argument list at the same time
+	runConcurrently := func(argLists ...[]string) []result {
+		results := make([]result, len(argLists))
+		var wg sync.WaitGroup
+		for i, args := range argLists {
+			wg.Add(1)
+			go func(i int, args []string) {
+				defer wg.Done()
+				cmd := exec.Command(binPath, args...)
+				cmd.Dir = repoPath
+				var stderr strings.Builder
+				cmd.Stderr = &stderr
+				out, err := cmd.Output()
+				results[i] = result{stdout: strings.TrimSpace(string(out)), stderr: stderr.String(), err: err}
+			}(i, args)
+		}
+		wg.Wait()
+		return results
+	}
 
-	// Give it time to start and acquire the lock (increased from 500ms for reliability)
-	time.Sleep(2 * time.Second)
+	// 1. Different branches are created in parallel; slow setup does not hold the repository lock
+	t.Run("Parallel creation", func(t *testing.T) {
+		writeConfig(`{
+			"defaultBranch": "main",
+			"postCreateCmd": ["sleep 3"]
+		}`)
 
-	// 3. Attempt to run second wt command
-	cmd2 := exec.Command(binPath, "feature/fast")
-	cmd2.Dir = repoPath
-	out2, err := cmd2.CombinedOutput()
+		const n = 4
+		var argLists [][]string
+		for i := 0; i < n; i++ {
+			argLists = append(argLists, []string{"--trust", fmt.Sprintf("feature/parallel-%d", i)})
+		}
 
-	// 4. Verify second command failed with lock error
-	if err == nil {
-		t.Errorf("expected second wt command to fail due to lock, but it succeeded")
-	}
-	if !strings.Contains(string(out2), "another wt operation is in progress") {
-		t.Errorf("expected lock error message, got: %s", string(out2))
-	}
+		start := time.Now()
+		results := runConcurrently(argLists...)
+		elapsed := time.Since(start)
+
+		for i, r := range results {
+			if r.err != nil {
+				t.Errorf("wt %v failed: %s: %v", argLists[i], r.stderr, r.err)
+				continue
+			}
+			want := filepath.Join(tempDir, "repo.wt", fmt.Sprintf("feature-parallel-%d", i))
+			if r.stdout != want {
+				t.Errorf("expected %s, got %s", want, r.stdout)
+			}
+		}
+		// Serialized, the setup alone would take n*3s
+		if elapsed > 9*time.Second {
+			t.Errorf("expected %d creations to run in parallel, took %s", n, elapsed)
+		}
+	})
+
+	// 2. Duplicate requests for one branch wait for the first and return the same path
+	t.Run("Duplicate requests", func(t *testing.T) {
+		runsFile := filepath.Join(tempDir, "runs")
+		writeConfig(`{
+			"defaultBranch": "main",
+			"postCreateCmd": [
+				{"argv": ["sh", "-c", "sleep 2; echo run >> ` + runsFile + `"]}
+			]
+		}`)
+
+		results := runConcurrently(
+			[]string{"--trust", "feature/same"},
+			[]string{"--trust", "feature/same"},
+			[]string{"--trust", "feature/same"},
+		)
+
+		want := filepath.Join(tempDir, "repo.wt", "feature-same")
+		for _, r := range results {
+			if r.err != nil {
+				t.Errorf("wt feature/same failed: %s: %v", r.stderr, r.err)
+				continue
+			}
+			if r.stdout != want {
+				t.Errorf("expected %s, got %s", want, r.stdout)
+			}
+		}
+
+		data, err := os.ReadFile(runsFile)
+		if err != nil {
+			t.Fatalf("postCreateCmd did not run: %v", err)
+		}
+		if runs := strings.Count(string(data), "run"); runs != 1 {
+			t.Errorf("expected postCreateCmd to run once, ran %d times", runs)
+		}
+	})
+
+	// 2.1 A request arriving after the worktree was registered still waits for
+	// the setup commands, beyond the lock timeout, and sees their rollback
+	t.Run("Duplicate request during setup", func(t *testing.T) {
+		marker := filepath.Join(tempDir, "failed-once")
+		writeConfig(`{
+			"defaultBranch": "main",
+			"postCreateCmd": [
+				{"argv": ["sh", "-c", "sleep 2; [ -e ` + marker + ` ] || { touch ` + marker + `; exit 1; }"]}
+			]
+		}`)
+
+		want := filepath.Join(tempDir, "repo.wt", "feature-late")
+		first := exec.Command(binPath, "--trust", "feature/late")
+		first.Dir = repoPath
+		if err := first.Start(); err != nil {
+			t.Fatal(err)
+		}
+		for deadline := time.Now().Add(10 * time.Second); ; {
+			if _, err := os.Stat(want); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("worktree %s was not registered", want)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		second := exec.Command(binPath, "--trust", "--lock-timeout", "500ms", "feature/late")
+		second.Dir = repoPath
+		var stderr strings.Builder
+		second.Stderr = &stderr
+		out, err := second.Output()
+		if err != nil {
+			t.Errorf("expected the second request to create the worktree after the rollback: %s: %v", stderr.String(), err)
+		}
+		if got := strings.TrimSpace(string(out)); got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("expected the returned worktree to exist: %v", err)
+		}
+		if err := first.Wait(); err == nil {
+			t.Errorf("expected the first request to fail its setup")
+		}
+	})
+
+	// 3. A busy repository lock times out with the holder's details
+	t.Run("Repository lock timeout", func(t *testing.T) {
+		writeConfig(`{"defaultBranch": "main"}`)
+
+		unlock, err := git.AcquireLock(repoPath, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		locksCmd := exec.Command(binPath, "locks")
+		locksCmd.Dir = repoPath
+		locksOut, err := locksCmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("wt locks failed: %s: %v", locksOut, err)
+		}
+		if row := lockRow(string(locksOut), "repository"); len(row) < 3 || row[1] != "held" || row[2] != fmt.Sprint(os.Getpid()) {
+			t.Errorf("expected wt locks to show this test as holder, got: %s", locksOut)
+		}
+		if !strings.Contains(string(locksOut), filepath.Join(repoPath, ".git", "wt.lock")) {
+			t.Errorf("expected lock in the git common dir, got: %s", locksOut)
+		}
+
+		// --lock-timeout overrides the default wait
+		start := time.Now()
+		cmd := exec.Command(binPath, "--lock-timeout", "200ms", "feature/blocked")
+		cmd.Dir = repoPath
+		out, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("expected wt to fail while the repository lock is held, got: %s", out)
+		}
+		if !strings.Contains(string(out), "another wt operation is in progress") ||
+			!strings.Contains(string(out), fmt.Sprintf("held by PID %d", os.Getpid())) {
+			t.Errorf("expected lock error naming the holder, got: %s", out)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("expected --lock-timeout 200ms to give up quickly, took %s", elapsed)
+		}
+
+		if err := unlock(); err != nil {
+			t.Fatal(err)
+		}
+
+		// The lock is free again and its owner info removed
+		locksCmd = exec.Command(binPath, "locks")
+		locksCmd.Dir = repoPath
+		locksOut, _ = locksCmd.CombinedOutput()
+		if row := lockRow(string(locksOut), "repository"); len(row) < 2 || row[1] != "free" {
+			t.Errorf("expected lock to be free after release, got: %s", locksOut)
+		}
+	})
+}
 
-	// Wait for first command to finish
-	if err := <-errChan; err != nil {
-		t.Errorf("first wt command failed: %v", err)
+// lockRow returns the fields of the wt locks row for the named lock
+func lockRow(out, name string) []string {
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			return fields
+		}
 	}
+	return nil
 }
 
 func runCmd(dir string, name string, args ...string) {
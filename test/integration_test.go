@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -24,6 +25,9 @@ func TestIntegration(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// Keep trust approvals out of the user's state directory
+	t.Setenv("XDG_STATE_HOME", filepath.Join(tempDir, "state"))
+
 	repoPath := filepath.Join(tempDir, "repo")
 	if err := os.MkdirAll(repoPath, 0755); err != nil {
 		t.Fatal(err)
@@ -133,6 +137,38 @@ func TestIntegration(t *testing.T) {
 		}
 	})
 
+	// Test 2.2: Every command resolves the main repository from a linked worktree
+	t.Run("Commands from linked worktree", func(t *testing.T) {
+		subDir := filepath.Join(featureXPath, "sub")
+		if err := os.MkdirAll(subDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		// Worktree base comes from the main repository, not the linked worktree
+		got := runWtIn(subDir, "feature/from-linked")
+		want := filepath.Join(tempDir, "repo.wt", "feature-from-linked")
+		if got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+
+		// Config is read from the main repository (defaultBranch override)
+		health := runWtIn(subDir, "health")
+		if !strings.Contains(health, "[OK] Repo root: "+repoPath+"\n") {
+			t.Errorf("expected health to report main repo root, got: %s", health)
+		}
+		if !strings.Contains(health, "main (override)") {
+			t.Errorf("expected config to be read from main repo, got: %s", health)
+		}
+
+		runWtIn(subDir, "remove", "feature/from-linked")
+		if _, err := os.Stat(want); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", want)
+		}
+		if _, err := os.Stat(filepath.Join(featureXPath, ".git", "wt.lock")); err == nil {
+			t.Errorf("lock file should not be created inside the linked worktree")
+		}
+	})
+
 	// Test 3: Idempotency
 	t.Run("Idempotency", func(t *testing.T) {
 		got1 := runWt("feature/x")
@@ -142,6 +178,52 @@ func TestIntegration(t *testing.T) {
 		}
 	})
 
+	// Test 3.1: Run command inside (possibly new) worktree
+	t.Run("Run command", func(t *testing.T) {
+		got := runWt("run", "feature/run", "--", "pwd")
+		wantSuffix := "repo.wt/feature-run"
+		if !strings.HasSuffix(got, wantSuffix) {
+			t.Errorf("expected run to execute in %s, got %s", wantSuffix, got)
+		}
+
+		// Exit code of the child is propagated
+		cmd := exec.Command(binPath, "run", "feature/run", "--", "sh", "-c", "exit 3")
+		cmd.Dir = repoPath
+		err := cmd.Run()
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok || exitErr.ExitCode() != 3 {
+			t.Errorf("expected exit code 3, got %v", err)
+		}
+
+		// Missing command after -- is a usage error
+		cmd = exec.Command(binPath, "run", "feature/run")
+		cmd.Dir = repoPath
+		if err := cmd.Run(); err == nil {
+			t.Errorf("expected failure for run without command, but succeeded")
+		}
+	})
+
+	// Test 3.2: Run command across worktrees
+	t.Run("Foreach", func(t *testing.T) {
+		out := runWt("foreach", "--filter", "feature/*", "--", "pwd")
+		if !strings.Contains(out, "[feature/x] "+featureXPath) {
+			t.Errorf("expected prefixed output for feature/x, got: %s", out)
+		}
+		if !strings.Contains(out, "[feature/run] ") {
+			t.Errorf("expected prefixed output for feature/run, got: %s", out)
+		}
+		if !strings.Contains(out, "BRANCH") || !strings.Contains(out, "EXIT") {
+			t.Errorf("expected summary table, got: %s", out)
+		}
+
+		// Failure in any worktree makes foreach fail
+		cmd := exec.Command(binPath, "foreach", "--", "sh", "-c", "exit 2")
+		cmd.Dir = repoPath
+		if err := cmd.Run(); err == nil {
+			t.Errorf("expected foreach to fail when command fails")
+		}
+	})
+
 	// Test 4: List worktrees
 	t.Run("List worktrees", func(t *testing.T) {
 		got := runWt()
@@ -151,6 +233,53 @@ func TestIntegration(t *testing.T) {
 		if !strings.Contains(got, "feature/x") {
 			t.Errorf("expected list to contain 'feature/x', got: %s", got)
 		}
+
+		// --format renders a template per worktree
+		if err := os.WriteFile(filepath.Join(featureXPath, "dirty.txt"), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = os.Remove(filepath.Join(featureXPath, "dirty.txt"))
+		}()
+		got = runWt("--format", "{{.Branch}}|{{.IsMain}}|{{if .Dirty}}*{{end}}")
+		lines := strings.Split(got, "\n")
+		if len(lines) < 2 || !strings.HasPrefix(lines[0], "main|true|") {
+			t.Errorf("expected main worktree first, got: %s", got)
+		}
+		if !strings.Contains(got, "feature/x|false|*") {
+			t.Errorf("expected feature/x to be marked dirty, got: %s", got)
+		}
+	})
+
+	// Test 4.1: Status view
+	t.Run("Status", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(featureXPath, "untracked.txt"), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = os.Remove(filepath.Join(featureXPath, "untracked.txt"))
+		}()
+
+		out := runWt("status")
+		if !strings.Contains(out, "BRANCH") || !strings.Contains(out, "LAST COMMIT") {
+			t.Errorf("expected status header, got: %s", out)
+		}
+		var featureLine string
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, "feature/x ") {
+				featureLine = line
+			}
+		}
+		if !strings.Contains(featureLine, "?1") {
+			t.Errorf("expected feature/x to report one untracked file, got: %s", featureLine)
+		}
+		if !strings.Contains(featureLine, "yes") {
+			t.Errorf("expected feature/x to be reported as merged, got: %s", featureLine)
+		}
+
+		if long := runWt("--long"); !strings.Contains(long, "feature/x") || !strings.Contains(long, "CHANGES") {
+			t.Errorf("expected wt --long to show status table, got: %s", long)
+		}
 	})
 
 	// Test 5: Config and PostCreateCmd
@@ -163,6 +292,7 @@ func TestIntegration(t *testing.T) {
 		if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(configContent), 0644); err != nil {
 			t.Fatal(err)
 		}
+		runWt("trust")
 
 		got := runWt("feature/y")
 		if _, err := os.Stat(filepath.Join(got, "created.txt")); os.IsNotExist(err) {
@@ -173,6 +303,100 @@ func TestIntegration(t *testing.T) {
 		}
 	})
 
+	// Test 5.1: PostCreateCmd receives worktree context
+	t.Run("PostCreateCmd environment", func(t *testing.T) {
+		configContent := `{
+			"defaultBranch": "main",
+			"postCreateCmd": ["env"]
+		}`
+		if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(configContent), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runWt("trust")
+
+		out := runWt("feature/env")
+		for _, want := range []string{
+			"WT_BRANCH=feature/env",
+			"WT_REPO_ROOT=" + repoPath,
+			"WT_BASE=main",
+			"WT_DEFAULT_BRANCH=main",
+			"WT_IS_NEW_BRANCH=true",
+			"WT_DIR_NAME=feature-env",
+			"WT_PATH=" + filepath.Join(tempDir, "repo.wt", "feature-env"),
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected postCreateCmd environment to contain %s, got: %s", want, out)
+			}
+		}
+	})
+
+	// Test 5.2: Quoted and structured postCreateCmd entries
+	t.Run("PostCreateCmd quoting and structured form", func(t *testing.T) {
+		configContent := `{
+			"defaultBranch": "main",
+			"postCreateCmd": [
+				"touch \"with space.txt\" 'a|b.txt'",
+				{"argv": ["mkdir", "-p", "sub dir"]},
+				{"argv": ["touch", "nested.txt"], "dir": "sub dir", "timeout": "30s"}
+			]
+		}`
+		if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(configContent), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runWt("trust")
+
+		got := runWt("feature/quoting")
+		for _, name := range []string{"with space.txt", "a|b.txt", filepath.Join("sub dir", "nested.txt")} {
+			if _, err := os.Stat(filepath.Join(got, name)); err != nil {
+				t.Errorf("expected %q to be created by postCreateCmd: %v", name, err)
+			}
+		}
+	})
+
+	// Test 5.3: Command output never reaches stdout and is kept in a log
+	t.Run("PostCreateCmd output and logs", func(t *testing.T) {
+		configContent := `{
+			"defaultBranch": "main",
+			"postCreateCmd": ["echo setup-output", "sh -c 'echo setup-error >&2'"]
+		}`
+		if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(configContent), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runWt("trust")
+
+		cmd := exec.Command(binPath, "feature/noisy")
+		cmd.Dir = repoPath
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		stdout, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("wt feature/noisy failed: %s: %v", stderr.String(), err)
+		}
+		want := filepath.Join(tempDir, "repo.wt", "feature-noisy")
+		if got := string(stdout); got != want+"\n" {
+			t.Errorf("expected stdout to be only the path %q, got %q", want, got)
+		}
+		if !strings.Contains(stderr.String(), "setup-output") || !strings.Contains(stderr.String(), "setup-error") {
+			t.Errorf("expected command output on stderr, got: %s", stderr.String())
+		}
+
+		logs := runWt("logs", "feature/noisy")
+		for _, want := range []string{"$ echo setup-output", "setup-output", "setup-error"} {
+			if !strings.Contains(logs, want) {
+				t.Errorf("expected wt logs to contain %q, got: %s", want, logs)
+			}
+		}
+		logPath := runWt("logs", "--path", "feature/noisy")
+		if !strings.HasSuffix(logPath, filepath.Join(".git", "wt", "logs", "feature-noisy", "post-create.log")) {
+			t.Errorf("unexpected log path: %s", logPath)
+		}
+
+		// Logs are read from the shared git dir, also inside a linked worktree
+		if got := runWtIn(want, "logs", "--path", "feature/noisy"); got != logPath {
+			t.Errorf("expected same log path from linked worktree, got %s", got)
+		}
+	})
+
 	// Test 8: Remove worktree with branch deletion
 	t.Run("Remove worktree with branch deletion", func(t *testing.T) {
 		configContent := `{
@@ -277,6 +501,628 @@ func TestIntegration(t *testing.T) {
 		if err := cmd.Run(); err == nil {
 			t.Errorf("merged-branch should have been deleted")
 		}
+
+		// 8. Squash merges are only found with --strategy squash
+		squashPath := runWt("squash-branch")
+		for _, name := range []string{"squash1.txt", "squash2.txt"} {
+			if err := os.WriteFile(filepath.Join(squashPath, name), []byte(name), 0644); err != nil {
+				t.Fatal(err)
+			}
+			runGit(t, squashPath, "add", name)
+			runGit(t, squashPath, "commit", "-m", name)
+		}
+		if err := os.WriteFile(filepath.Join(repoPath, "meanwhile.txt"), []byte("main moved on"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, repoPath, "add", "meanwhile.txt")
+		runGit(t, repoPath, "commit", "-m", "meanwhile on main")
+		runGit(t, repoPath, "merge", "--squash", "squash-branch")
+		runGit(t, repoPath, "commit", "-m", "squash-branch (#1)")
+
+		if out := runWt("prune", "--dry-run"); strings.Contains(out, "squash-branch") {
+			t.Errorf("expected the ancestry strategy to miss squash-branch, got: %s", out)
+		}
+		if out := runWt("prune", "--dry-run", "--strategy", "squash"); !strings.Contains(out, "squash-branch (squashed)") {
+			t.Errorf("expected squash-branch to be found as squashed, got: %s", out)
+		}
+		if out := runWt("prune", "--strategy", "squash"); !strings.Contains(out, "Pruned squash-branch (squashed)") {
+			t.Errorf("expected prune to report squash-branch with its rule, got: %s", out)
+		}
+		if out := runWt(); strings.Contains(out, "squash-branch") || !strings.Contains(out, "unmerged-branch") {
+			t.Errorf("expected only squash-branch to be pruned, got: %s", out)
+		}
+
+		// 9. Unmerged worktrees are selected by age, still keeping dirty ones
+		stalePath := runWt("stale-branch")
+		if _, err := os.Stat(filepath.Join(repoPath, ".git", "wt", "logs", "stale-branch", "last-access")); err != nil {
+			t.Errorf("expected wt to record the access to stale-branch: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(stalePath, "stale.txt"), []byte("experiment"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, stalePath, "add", "stale.txt")
+		commit := exec.Command("git", "commit", "-q", "-m", "old experiment")
+		commit.Dir = stalePath
+		commit.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2020-01-01T00:00:00Z")
+		if out, err := commit.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %s: %v", out, err)
+		}
+
+		out = runWt("prune", "--dry-run", "--older-than", "30d")
+		if !strings.Contains(out, "stale-branch (older-than: last commit ") || strings.Contains(out, "unmerged-branch") {
+			t.Errorf("expected only stale-branch to be older than 30d, got: %s", out)
+		}
+		if out := runWt("prune", "--dry-run", "--inactive-for", "30d"); strings.Contains(out, "stale-branch") {
+			t.Errorf("expected the just used stale-branch to be active, got: %s", out)
+		}
+
+		if err := os.WriteFile(filepath.Join(stalePath, "scratch.txt"), []byte("wip"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		out = runWt("prune", "--older-than", "30d")
+		if !strings.Contains(out, "Skipped stale-branch (older-than: last commit ") || !strings.Contains(out, "dirty (use --force to prune)") {
+			t.Errorf("expected prune to report the dirty stale-branch as skipped, got: %s", out)
+		}
+		if _, err := os.Stat(stalePath); err != nil {
+			t.Errorf("expected the dirty stale-branch worktree to be kept: %v", err)
+		}
+		out = runWt("prune", "--older-than", "30d", "--force")
+		if !strings.Contains(out, "Pruned stale-branch (older-than: last commit ") || !strings.Contains(out, "; branch kept") {
+			t.Errorf("expected prune to report stale-branch with its timestamps and kept branch, got: %s", out)
+		}
+		if _, err := os.Stat(stalePath); !os.IsNotExist(err) {
+			t.Errorf("expected stale-branch to be pruned, got: %v", err)
+		}
+
+		// deleteBranchWithWorktree spares the unmerged branch, also with
+		// --force; only --delete-unmerged deletes it
+		if out := gitOutput(t, repoPath, "branch", "--list", "stale-branch"); out == "" {
+			t.Errorf("expected the unmerged stale-branch to survive the prune")
+		}
+		runWt("stale-branch")
+		if out := runWt("prune", "--older-than", "30d", "--delete-unmerged"); strings.Contains(out, "branch kept") {
+			t.Errorf("expected --delete-unmerged not to keep the branch, got: %s", out)
+		}
+		if out := gitOutput(t, repoPath, "branch", "--list", "stale-branch"); out != "" {
+			t.Errorf("expected --delete-unmerged to delete stale-branch, got %q", out)
+		}
+
+		cmd = exec.Command(binPath, "prune", "--older-than", "soon")
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "invalid age") {
+			t.Errorf("expected an invalid age to be rejected, got: %s", out)
+		}
+	})
+
+	// Test 10.1: Machine-readable output
+	t.Run("JSON output", func(t *testing.T) {
+		wtJSON := func(v any, args ...string) {
+			cmd := exec.Command(binPath, append([]string{"--json"}, args...)...)
+			cmd.Dir = repoPath
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("wt --json %v failed: %s: %v", args, string(out), err)
+			}
+			if err := json.Unmarshal(out, v); err != nil {
+				t.Fatalf("wt --json %v printed invalid JSON: %v\n%s", args, err, string(out))
+			}
+		}
+
+		var list struct {
+			SchemaVersion int `json:"schemaVersion"`
+			Worktrees     []struct {
+				Branch string `json:"branch"`
+				Path   string `json:"path"`
+			} `json:"worktrees"`
+		}
+		wtJSON(&list)
+		if list.SchemaVersion != 1 {
+			t.Errorf("expected schemaVersion 1, got %d", list.SchemaVersion)
+		}
+		if len(list.Worktrees) == 0 || list.Worktrees[0].Branch != "main" || list.Worktrees[0].Path != repoPath {
+			t.Errorf("expected main worktree first, got %+v", list.Worktrees)
+		}
+
+		var status struct {
+			SchemaVersion int `json:"schemaVersion"`
+			Worktrees     []struct {
+				Branch  string `json:"branch"`
+				IsMain  bool   `json:"isMain"`
+				Head    string `json:"head"`
+				Merged  bool   `json:"merged"`
+				Changes struct {
+					Untracked int `json:"untracked"`
+				} `json:"changes"`
+			} `json:"worktrees"`
+		}
+		wtJSON(&status, "status")
+		if len(status.Worktrees) != len(list.Worktrees) || !status.Worktrees[0].IsMain || status.Worktrees[0].Head == "" {
+			t.Errorf("unexpected status output: %+v", status.Worktrees)
+		}
+
+		var health struct {
+			SchemaVersion int  `json:"schemaVersion"`
+			OK            bool `json:"ok"`
+			Checks        []struct {
+				Name  string `json:"name"`
+				Level string `json:"level"`
+			} `json:"checks"`
+		}
+		wtJSON(&health, "health")
+		if !health.OK || len(health.Checks) == 0 || health.Checks[0].Level != "OK" {
+			t.Errorf("unexpected health output: %+v", health)
+		}
+
+		// A dirty merged worktree is reported as skipped with its reason
+		runGit(t, repoPath, "branch", "json-merged")
+		runGit(t, repoPath, "branch", "json-dirty")
+		runWt("json-merged")
+		dirtyPath := runWt("json-dirty")
+		if err := os.WriteFile(filepath.Join(dirtyPath, "dirty.txt"), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		type pruneEntry struct {
+			Branch string `json:"branch"`
+			Path   string `json:"path"`
+			Reason string `json:"reason"`
+		}
+		var prune struct {
+			SchemaVersion int          `json:"schemaVersion"`
+			DryRun        bool         `json:"dryRun"`
+			Pruned        []pruneEntry `json:"pruned"`
+			Skipped       []pruneEntry `json:"skipped"`
+		}
+		wtJSON(&prune, "prune", "--dry-run")
+		if !prune.DryRun || len(prune.Pruned) != 1 || prune.Pruned[0].Branch != "json-merged" {
+			t.Errorf("expected json-merged as the only prune candidate, got %+v", prune.Pruned)
+		}
+		if len(prune.Skipped) != 1 || prune.Skipped[0].Branch != "json-dirty" || prune.Skipped[0].Reason != "dirty" {
+			t.Errorf("expected json-dirty to be skipped as dirty, got %+v", prune.Skipped)
+		}
+
+		runWt("remove", "json-merged")
+		runWt("remove", "--force", "json-dirty")
+
+		// Commands without JSON output reject the flag
+		cmd := exec.Command(binPath, "--json", "locks")
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "not supported") {
+			t.Errorf("expected --json to be rejected by wt locks, got: %s", string(out))
+		}
+	})
+
+	// Test 10.2: Detached and prunable worktrees are addressed by path or commit
+	t.Run("Detached and prunable worktrees", func(t *testing.T) {
+		detachedA := filepath.Join(tempDir, "repo.wt", "detached-a")
+		detachedB := filepath.Join(tempDir, "repo.wt", "detached-b")
+		runGit(t, repoPath, "worktree", "add", "--detach", detachedA)
+		runGit(t, repoPath, "worktree", "add", "--detach", detachedB)
+		runGit(t, detachedA, "commit", "--allow-empty", "-m", "detached commit")
+
+		var list struct {
+			Worktrees []struct {
+				Path     string `json:"path"`
+				Head     string `json:"head"`
+				Detached bool   `json:"detached"`
+				Prunable bool   `json:"prunable"`
+			} `json:"worktrees"`
+		}
+		readList := func() {
+			cmd := exec.Command(binPath, "--json")
+			cmd.Dir = repoPath
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("wt --json failed: %v", err)
+			}
+			if err := json.Unmarshal(out, &list); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		readList()
+		headA := ""
+		for _, wt := range list.Worktrees {
+			if wt.Path == detachedA {
+				headA = wt.Head
+				if !wt.Detached {
+					t.Errorf("expected %s to be reported as detached", detachedA)
+				}
+			}
+		}
+		if len(headA) < 8 {
+			t.Fatalf("expected HEAD for %s, got %+v", detachedA, list.Worktrees)
+		}
+
+		// wt, wt cd and wt run find detached worktrees by path or commit
+		// rather than creating a branch of that name
+		cmd := exec.Command(binPath, "(detached)")
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "matches 2 worktrees") {
+			t.Errorf("expected (detached) to be ambiguous, got: %s", string(out))
+		}
+		if got := runWt(headA[:8]); got != detachedA {
+			t.Errorf("expected wt %s to return %s, got %s", headA[:8], detachedA, got)
+		}
+		relB, err := filepath.Rel(repoPath, detachedB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantB, _ := filepath.EvalSymlinks(detachedB)
+		if got := runWt("run", relB, "--", "pwd"); got != wantB {
+			t.Errorf("expected wt run %s to run in %s, got %s", relB, wantB, got)
+		}
+		if out := gitOutput(t, repoPath, "branch", "--list", relB); out != "" {
+			t.Errorf("expected no branch %s to be created, got %q", relB, out)
+		}
+
+		// Directory names of worktrees with a branch remain new branch names,
+		// colliding with the existing worktree
+		runWt("feature/z")
+		cmd = exec.Command(binPath, "run", "feature-z", "--", "pwd")
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "collision") {
+			t.Errorf("expected feature-z to collide with feature/z, got: %s", string(out))
+		}
+		runWt("remove", "feature/z")
+
+		// Remove by short commit id
+		runWt("remove", headA[:8])
+		if _, err := os.Stat(detachedA); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed by commit", detachedA)
+		}
+
+		// A worktree whose directory is gone becomes prunable and can still be removed
+		if err := os.RemoveAll(detachedB); err != nil {
+			t.Fatal(err)
+		}
+		readList()
+		prunable := false
+		for _, wt := range list.Worktrees {
+			prunable = prunable || (wt.Path == detachedB && wt.Prunable)
+		}
+		if !prunable {
+			t.Errorf("expected %s to be reported as prunable, got %+v", detachedB, list.Worktrees)
+		}
+		if status := runWt("status"); !strings.Contains(status, "prunable") {
+			t.Errorf("expected status to report the prunable worktree, got: %s", status)
+		}
+		runWt("remove", detachedB)
+		if out := runWt(); strings.Contains(out, detachedB) {
+			t.Errorf("expected %s to be removed by path, got: %s", detachedB, out)
+		}
+	})
+
+	// Test 10.3: Locked worktrees are protected from prune and remove
+	t.Run("Lock worktrees", func(t *testing.T) {
+		runGit(t, repoPath, "branch", "lock-merged")
+		path := runWt("lock-merged")
+		tryWt := func(args ...string) (string, error) {
+			cmd := exec.Command(binPath, args...)
+			cmd.Dir = repoPath
+			out, err := cmd.CombinedOutput()
+			return string(out), err
+		}
+
+		runWt("lock", "lock-merged", "--reason", "usb drive")
+		if out := runWt(); !strings.Contains(out, "lock-merged\t"+path+"\tlocked: usb drive") {
+			t.Errorf("expected list to show the lock reason, got: %s", out)
+		}
+		if out := runWt("status"); !strings.Contains(out, "usb drive") || !strings.Contains(out, "LOCKED") {
+			t.Errorf("expected status to show the lock reason, got: %s", out)
+		}
+		if out, err := tryWt("lock", "lock-merged"); err == nil || !strings.Contains(out, "already locked") {
+			t.Errorf("expected locking twice to fail, got: %s", out)
+		}
+		if out, err := tryWt("remove", "lock-merged"); err == nil || !strings.Contains(out, "locked") {
+			t.Errorf("expected remove to refuse a locked worktree, got: %s", out)
+		}
+
+		var prune struct {
+			Pruned  []struct{ Branch string } `json:"pruned"`
+			Skipped []struct {
+				Branch string `json:"branch"`
+				Reason string `json:"reason"`
+			} `json:"skipped"`
+		}
+		cmd := exec.Command(binPath, "prune", "--dry-run", "--json")
+		cmd.Dir = repoPath
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("wt prune --dry-run --json failed: %v", err)
+		}
+		if err := json.Unmarshal(out, &prune); err != nil {
+			t.Fatal(err)
+		}
+		skipped := false
+		for _, s := range prune.Skipped {
+			skipped = skipped || (s.Branch == "lock-merged" && s.Reason == "locked: usb drive")
+		}
+		for _, p := range prune.Pruned {
+			if p.Branch == "lock-merged" {
+				t.Errorf("locked worktree should not be a prune candidate")
+			}
+		}
+		if !skipped {
+			t.Errorf("expected prune to skip the locked worktree, got: %s", string(out))
+		}
+
+		runWt("unlock", "lock-merged")
+		if out := runWt(); strings.Contains(out, "locked") {
+			t.Errorf("expected no lock after wt unlock, got: %s", out)
+		}
+
+		runWt("lock", path)
+		runWt("remove", "--ignore-lock", "lock-merged")
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected --ignore-lock to remove the locked worktree")
+		}
+	})
+
+	// Test 10.4: Moving worktrees
+	t.Run("Move worktrees", func(t *testing.T) {
+		configPath := filepath.Join(repoPath, ".wt.config.json")
+		original, err := os.ReadFile(configPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = os.WriteFile(configPath, original, 0644)
+		}()
+
+		runGit(t, repoPath, "branch", "move-me")
+		oldPath := runWt("move-me")
+
+		// Explicit destination; the new path is printed
+		newPath := filepath.Join(tempDir, "moved", "move-me")
+		if got := runWt("mv", "move-me", newPath); got != newPath {
+			t.Errorf("expected wt mv to print %s, got: %s", newPath, got)
+		}
+		if got := runWt("move-me"); got != newPath {
+			t.Errorf("expected wt move-me to return the moved path, got: %s", got)
+		}
+		if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be gone after the move", oldPath)
+		}
+
+		// --to-template moves every worktree to the configured location
+		var cfg map[string]any
+		if err := json.Unmarshal(original, &cfg); err != nil {
+			t.Fatal(err)
+		}
+		cfg["worktreePathTemplate"] = "$REPO_PATH.trees"
+		data, _ := json.Marshal(cfg)
+		if err := os.WriteFile(configPath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		dryRun := runWt("mv", "--to-template", "--dry-run")
+		if !strings.Contains(dryRun, "move-me: "+newPath+" -> "+filepath.Join(tempDir, "repo.trees", "move-me")) {
+			t.Errorf("expected dry run to list the move, got: %s", dryRun)
+		}
+		if _, err := os.Stat(newPath); err != nil {
+			t.Errorf("dry run should not move anything: %v", err)
+		}
+		runWt("mv", "--to-template")
+		for _, line := range strings.Split(runWt(), "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) >= 2 && fields[1] != repoPath && !strings.HasPrefix(fields[1], filepath.Join(tempDir, "repo.trees")+string(filepath.Separator)) {
+				t.Errorf("expected all worktrees below repo.trees, got: %s", line)
+			}
+		}
+
+		// Back to the original template
+		if err := os.WriteFile(configPath, original, 0644); err != nil {
+			t.Fatal(err)
+		}
+		runWt("mv", "--to-template")
+		if got := runWt("move-me"); got != oldPath {
+			t.Errorf("expected move-me back at %s, got: %s", oldPath, got)
+		}
+
+		// Moves across filesystems refuse dirty worktrees unless forced
+		shm, err := os.MkdirTemp("/dev/shm", "wt-test-*")
+		if err != nil {
+			t.Log("skipping cross-filesystem move: /dev/shm not available")
+		} else {
+			defer func() {
+				_ = os.RemoveAll(shm)
+			}()
+			if err := os.WriteFile(filepath.Join(oldPath, "dirty.txt"), []byte("x"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cmd := exec.Command(binPath, "mv", "move-me", shm)
+			cmd.Dir = repoPath
+			if out, err := cmd.CombinedOutput(); err == nil {
+				t.Log("skipping cross-filesystem move: /dev/shm is on the same filesystem")
+				runWt("mv", "move-me")
+			} else if !strings.Contains(string(out), "another filesystem") {
+				t.Errorf("expected dirty cross-filesystem move to be refused, got: %s", string(out))
+			} else {
+				movedPath := runWt("mv", "--force", "move-me", shm)
+				if _, err := os.Stat(filepath.Join(movedPath, "dirty.txt")); err != nil {
+					t.Errorf("expected uncommitted file to be copied: %v", err)
+				}
+				if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+					t.Errorf("expected %s to be deleted after a cross-filesystem move", oldPath)
+				}
+				runGit(t, movedPath, "status")
+				runWt("mv", "--force", "move-me")
+			}
+		}
+
+		runWt("remove", "--force", "move-me")
+	})
+
+	// Test 10.5: Renaming a worktree's branch and directory together
+	t.Run("Rename worktrees", func(t *testing.T) {
+		published := filepath.Join(tempDir, "published.git")
+		runGit(t, tempDir, "init", "--bare", "-q", published)
+		runGit(t, repoPath, "remote", "add", "published", published)
+		defer runGit(t, repoPath, "remote", "remove", "published")
+
+		runGit(t, repoPath, "branch", "agent-3")
+		oldPath := runWt("agent-3")
+		runGit(t, oldPath, "commit", "-q", "--allow-empty", "-m", "agent work")
+		runGit(t, oldPath, "push", "-q", "-u", "published", "agent-3")
+
+		newPath := filepath.Join(filepath.Dir(oldPath), "feature-login")
+		if got := runWt("rename", "agent-3", "feature/login"); !strings.HasSuffix(got, newPath) ||
+			!strings.Contains(got, "git push -u published feature/login") {
+			t.Errorf("expected wt rename to print %s and how to publish it, got: %s", newPath, got)
+		}
+		if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be gone after the rename", oldPath)
+		}
+		if got := gitOutput(t, newPath, "branch", "--show-current"); got != "feature/login" {
+			t.Errorf("expected feature/login checked out, got: %s", got)
+		}
+		// The upstream still names the pushed branch, so the renamed branch
+		// is not mistaken for one deleted on the remote
+		if got := gitOutput(t, repoPath, "config", "branch.feature/login.merge"); got != "refs/heads/agent-3" {
+			t.Errorf("expected the upstream to keep tracking agent-3, got: %s", got)
+		}
+		if out := runWt("--remote", "published", "prune", "--gone", "--fetch", "--dry-run"); strings.Contains(out, "feature/login") {
+			t.Errorf("expected the renamed branch not to be gone, got: %s", out)
+		}
+
+		// Collisions and the default branch are refused, and nothing changes
+		runGit(t, repoPath, "branch", "feature-login")
+		cmd := exec.Command(binPath, "rename", "feature/login", "feature-login")
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "already exists") {
+			t.Errorf("expected rename onto an existing branch to fail, got: %s", string(out))
+		}
+		runGit(t, repoPath, "branch", "-D", "feature-login")
+		cmd = exec.Command(binPath, "rename", "main", "trunk")
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("expected renaming the main worktree to fail, got: %s", string(out))
+		}
+		if got := runWt("feature/login"); got != newPath {
+			t.Errorf("expected failed renames to leave the worktree alone, got: %s", got)
+		}
+
+		runWt("remove", "--force", "feature/login")
+	})
+
+	// Test 10.6: Repairing worktrees moved behind git's back
+	t.Run("Repair worktrees", func(t *testing.T) {
+		runGit(t, repoPath, "branch", "repair-me")
+		oldPath := runWt("repair-me")
+		newPath := filepath.Join(filepath.Dir(oldPath), "repair-moved")
+		if err := os.Rename(oldPath, newPath); err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := exec.Command(binPath, "health")
+		cmd.Dir = repoPath
+		out, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(out), "[ERROR] Worktree links: "+newPath) {
+			t.Errorf("expected health to report the broken link, got: %s", string(out))
+		}
+
+		repaired := runWt("repair")
+		if !strings.Contains(repaired, "repaired "+newPath) {
+			t.Errorf("expected repair to report the fix, got: %s", repaired)
+		}
+		if got := runWt("repair-me"); got != newPath {
+			t.Errorf("expected repair-me at %s after the repair, got: %s", newPath, got)
+		}
+		runGit(t, newPath, "status")
+		if out := runWt("repair"); out != "Nothing to repair." {
+			t.Errorf("expected a second repair to do nothing, got: %s", out)
+		}
+
+		// Records of worktrees whose directory is gone are pruned
+		if err := os.RemoveAll(newPath); err != nil {
+			t.Fatal(err)
+		}
+		if out := runWt("repair"); !strings.Contains(out, "pruned "+newPath) {
+			t.Errorf("expected repair to prune the missing worktree, got: %s", out)
+		}
+		if out := runWt(); strings.Contains(out, "repair-me") {
+			t.Errorf("expected the pruned worktree to be gone, got: %s", out)
+		}
+		runGit(t, repoPath, "branch", "-D", "repair-me")
+	})
+
+	// Test 10.7: Branches from a remote other than origin
+	t.Run("Remote branches", func(t *testing.T) {
+		upstream := filepath.Join(tempDir, "upstream.git")
+		runGit(t, tempDir, "init", "--bare", "-q", upstream)
+		runGit(t, repoPath, "push", "-q", upstream, "main:refs/heads/trunk", "main:refs/heads/from-upstream")
+		runGit(t, repoPath, "remote", "add", "upstream", upstream)
+		defer runGit(t, repoPath, "remote", "remove", "upstream")
+		runGit(t, repoPath, "fetch", "-q", "upstream")
+
+		// <remote>/<branch> creates a local branch tracking the remote one
+		want := filepath.Join(tempDir, "repo.wt", "from-upstream")
+		if got := runWt("upstream/from-upstream"); got != want {
+			t.Errorf("expected worktree at %s, got: %s", want, got)
+		}
+		if got := gitOutput(t, want, "rev-parse", "--abbrev-ref", "@{upstream}"); got != "upstream/from-upstream" {
+			t.Errorf("expected from-upstream to track upstream/from-upstream, got: %s", got)
+		}
+		if got := runWt("upstream/from-upstream"); got != want {
+			t.Errorf("expected the existing worktree to be returned, got: %s", got)
+		}
+
+		// Deleting the branch on the remote (e.g. after a merged PR) leaves
+		// the unmerged local branch tracking [gone] after fetch --prune
+		if err := os.WriteFile(filepath.Join(want, "upstream.txt"), []byte("pushed"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, want, "add", "upstream.txt")
+		runGit(t, want, "commit", "-q", "-m", "pushed and merged upstream")
+		runGit(t, want, "push", "-q", "upstream", "from-upstream")
+		runGit(t, upstream, "branch", "-D", "from-upstream")
+		if out := runWt("--remote", "upstream", "prune", "--dry-run", "--gone"); strings.Contains(out, "from-upstream") {
+			t.Errorf("expected from-upstream not to be gone before fetching, got: %s", out)
+		}
+		if out := runWt("--remote", "upstream", "prune", "--dry-run", "--gone", "--fetch"); !strings.Contains(out, "from-upstream (gone") {
+			t.Errorf("expected from-upstream to be pruned as gone, got: %s", out)
+		}
+		if out := runWt("prune", "--dry-run"); strings.Contains(out, "from-upstream") {
+			t.Errorf("expected from-upstream to be kept without --gone, got: %s", out)
+		}
+		// The unmerged branch outlives its worktree; --force only concerns
+		// uncommitted changes, --delete-unmerged would delete it
+		if out := runWt("--remote", "upstream", "prune", "--gone", "--force"); !strings.Contains(out, "from-upstream (gone; branch kept") {
+			t.Errorf("expected prune to report the kept branch, got: %s", out)
+		}
+		if _, err := os.Stat(want); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be pruned", want)
+		}
+		if out := gitOutput(t, repoPath, "branch", "--list", "from-upstream"); out == "" {
+			t.Errorf("expected the unmerged from-upstream branch to be kept")
+		}
+		runGit(t, repoPath, "branch", "-D", "from-upstream")
+
+		// --remote selects the remote, e.g. for a fork whose origin is personal
+		if out := runWt("--remote", "upstream", "health"); !strings.Contains(out, "[OK] Remote: upstream") {
+			t.Errorf("expected health to use the upstream remote, got: %s", out)
+		}
+		if out := runWt("--remote", "nope", "health"); !strings.Contains(out, `[WARN] Remote: remote "nope" does not exist`) {
+			t.Errorf("expected health to warn about a missing remote, got: %s", out)
+		}
+	})
+
+	// Test 10.8: Default branch fallbacks without config or origin/HEAD
+	t.Run("Default branch detection", func(t *testing.T) {
+		fresh := filepath.Join(tempDir, "fresh")
+		runGit(t, tempDir, "init", "-q", "-b", "work", fresh)
+		runGit(t, fresh, "-c", "user.email=test@example.com", "-c", "user.name=test", "commit", "-q", "--allow-empty", "-m", "initial")
+		runGit(t, fresh, "branch", "trunk")
+
+		out := runWtIn(fresh, "health")
+		if !strings.Contains(out, "[WARN] Default branch: trunk (guessed from local branch; origin/HEAD is not set)") {
+			t.Errorf("expected health to report the fallback source, got: %s", out)
+		}
+
+		// Without a remote, the fix pins the guess in the config
+		runWtIn(fresh, "health", "--fix", "--yes")
+		if out := runWtIn(fresh, "health"); !strings.Contains(out, "[OK] Default branch: trunk (override)") {
+			t.Errorf("expected the fix to write defaultBranch, got: %s", out)
+		}
 	})
 
 	// Test 11: Init config
@@ -305,6 +1151,29 @@ func TestIntegration(t *testing.T) {
 			t.Errorf("expected health check to report OK repo root, got: %s", out)
 		}
 
+		// Directories in the worktree base git does not know about
+		orphan := filepath.Join(tempDir, "repo.wt", "leftover")
+		if err := os.MkdirAll(orphan, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if out := runWt("health"); !strings.Contains(out, "[WARN] Orphaned directories: "+orphan) {
+			t.Errorf("expected health to report the orphaned directory, got: %s", out)
+		}
+
+		// --fix previews by default and applies with --yes
+		if out := runWt("health", "--fix"); !strings.Contains(out, "delete directory "+orphan) || !strings.Contains(out, "Dry run") {
+			t.Errorf("expected a preview of the fix, got: %s", out)
+		}
+		if _, err := os.Stat(orphan); err != nil {
+			t.Errorf("expected the preview to change nothing: %v", err)
+		}
+		if out := runWt("health", "--fix", "--yes"); !strings.Contains(out, "fixed: delete directory "+orphan) {
+			t.Errorf("expected the fix to be reported, got: %s", out)
+		}
+		if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+			t.Errorf("expected --fix --yes to delete the orphaned directory")
+		}
+
 		// Test: invalid config
 		if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte("invalid"), 0644); err != nil {
 			t.Fatal(err)
@@ -333,6 +1202,7 @@ func TestIntegration(t *testing.T) {
 		if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(configContent), 0644); err != nil {
 			t.Fatal(err)
 		}
+		runWt("trust")
 
 		cmd := exec.Command(binPath, "feature/rollback-new")
 		cmd.Dir = repoPath
@@ -343,6 +1213,9 @@ func TestIntegration(t *testing.T) {
 		if !strings.Contains(string(out), "Rollback status: succeeded (worktree removed, branch deleted)") {
 			t.Errorf("expected specific rollback message, got: %s", string(out))
 		}
+		if logs := runWt("logs", "feature/rollback-new"); !strings.Contains(logs, "postCreateCmd 'false' failed") {
+			t.Errorf("expected post-create log to record the failure, got: %s", logs)
+		}
 
 		// Verify branch is gone
 		cmd = exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/feature/rollback-new")
@@ -370,6 +1243,125 @@ func TestIntegration(t *testing.T) {
 			t.Errorf("branch feature/rollback-existing should NOT have been deleted")
 		}
 	})
+
+	// Test 14: Lifecycle hooks
+	t.Run("Lifecycle hooks", func(t *testing.T) {
+		hookDir := filepath.Join(tempDir, "hooks")
+		if err := os.MkdirAll(hookDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		// 1. Failing pre-create aborts before anything is created
+		configContent := `{
+			"defaultBranch": "main",
+			"hooks": {"pre-create": ["false"]}
+		}`
+		if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(configContent), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runWt("trust")
+		cmd := exec.Command(binPath, "feature/hook-blocked")
+		cmd.Dir = repoPath
+		out, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("expected pre-create hook to abort creation, got: %s", string(out))
+		}
+		if !strings.Contains(string(out), "pre-create hook") {
+			t.Errorf("expected pre-create hook error, got: %s", string(out))
+		}
+		cmd = exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/feature/hook-blocked")
+		cmd.Dir = repoPath
+		if err := cmd.Run(); err == nil {
+			t.Errorf("branch feature/hook-blocked should not have been created")
+		}
+
+		// 2. post-create runs in the worktree; a failing post-remove is only reported
+		configContent = `{
+			"defaultBranch": "main",
+			"hooks": {
+				"post-create": ["touch post-create.txt"],
+				"pre-remove": ["touch ` + filepath.Join(hookDir, "pre-remove.txt") + `"],
+				"post-remove": ["false"]
+			}
+		}`
+		if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(configContent), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runWt("trust")
+		path := runWt("feature/hooks")
+		if _, err := os.Stat(filepath.Join(path, "post-create.txt")); err != nil {
+			t.Errorf("post-create hook did not run in worktree: %v", err)
+		}
+
+		// post-create.txt makes the worktree dirty
+		runWt("remove", "--force", "feature/hooks")
+		if _, err := os.Stat(filepath.Join(hookDir, "pre-remove.txt")); err != nil {
+			t.Errorf("pre-remove hook did not run: %v", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("worktree %s should have been removed despite failing post-remove hook", path)
+		}
+	})
+
+	// Test 15: Commands require approval
+	t.Run("Trust", func(t *testing.T) {
+		writeConfig := func(content string) {
+			if err := os.WriteFile(filepath.Join(repoPath, ".wt.config.json"), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tryWt := func(args ...string) (string, error) {
+			cmd := exec.Command(binPath, args...)
+			cmd.Dir = repoPath
+			out, err := cmd.CombinedOutput()
+			return string(out), err
+		}
+
+		writeConfig(`{"defaultBranch": "main", "postCreateCmd": ["touch trusted.txt"]}`)
+
+		// 1. Untrusted commands fail without a terminal and create nothing
+		out, err := tryWt("feature/untrusted")
+		if err == nil {
+			t.Errorf("expected untrusted config to fail, got: %s", out)
+		}
+		if !strings.Contains(out, "not trusted") {
+			t.Errorf("expected trust error, got: %s", out)
+		}
+		// stdin is /dev/null, a character device but not a terminal: no prompt
+		if strings.Contains(out, "defines these commands") {
+			t.Errorf("expected no trust prompt without a terminal, got: %s", out)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "repo.wt", "feature-untrusted")); !os.IsNotExist(err) {
+			t.Errorf("worktree should not have been created for untrusted config")
+		}
+		if health := runWt("health"); !strings.Contains(health, "[WARN] Trust") {
+			t.Errorf("expected health to warn about untrusted commands, got: %s", health)
+		}
+
+		// 2. --trust runs the commands without recording approval
+		path := runWt("--trust", "feature/untrusted")
+		if _, err := os.Stat(filepath.Join(path, "trusted.txt")); err != nil {
+			t.Errorf("postCreateCmd did not run with --trust: %v", err)
+		}
+		if _, err := tryWt("feature/still-untrusted"); err == nil {
+			t.Errorf("--trust should not record approval")
+		}
+
+		// 3. wt trust approves; changing the commands requires approval again
+		runWt("trust")
+		runWt("feature/trusted")
+		writeConfig(`{"defaultBranch": "main", "postCreateCmd": ["touch changed.txt"]}`)
+		if _, err := tryWt("feature/changed"); err == nil {
+			t.Errorf("expected changed commands to require approval again")
+		}
+
+		// 4. wt untrust revokes approval
+		runWt("trust")
+		runWt("untrust")
+		if _, err := tryWt("feature/revoked"); err == nil {
+			t.Errorf("expected untrusted config to fail after wt untrust")
+		}
+	})
 }
 
 func runGit(t *testing.T, dir string, args ...string) {
@@ -379,3 +1371,14 @@ func runGit(t *testing.T, dir string, args ...string) {
 		t.Fatalf("git %v failed: %s: %v", args, string(out), err)
 	}
 }
+
+// gitOutput runs git in dir and returns its trimmed stdout
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v failed: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}